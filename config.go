@@ -0,0 +1,355 @@
+package ObjectPool
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"time"
+)
+
+// UnlimitedObjectCount as MaxObjectCount lets the pool grow without limit.
+const UnlimitedObjectCount = uint32(0)
+
+const (
+	defaultIdleTime         = 5 * time.Minute
+	defaultEvictionInterval = time.Minute
+	defaultPriorityAging    = time.Second
+)
+
+// BorrowStrategy picks which idle object is lent.
+type BorrowStrategy int
+
+const (
+	// most recently returned first, keeps a hot working set. With the
+	// background evictor and without leak detection its borrows and returns
+	// take no lock, idle objects are then only reaped by the evictor.
+	BorrowLIFO BorrowStrategy = iota
+	// least recently returned first, spreads use evenly across objects.
+	BorrowFIFO
+	BorrowRandom
+)
+
+func (s BorrowStrategy) String() string {
+	switch s {
+	case BorrowLIFO:
+		return "lifo"
+	case BorrowFIFO:
+		return "fifo"
+	case BorrowRandom:
+		return "random"
+	}
+	return fmt.Sprintf("BorrowStrategy(%d)", int(s))
+}
+
+// Config holds every setting of an BasicPool.
+type Config struct {
+	MinObjectCount uint32
+	// UnlimitedObjectCount means no limit.
+	MaxObjectCount uint32
+	IdleTime       time.Duration
+	// max idle objects reaped by one ReturnObject(), zero derives it from
+	// the object count range.
+	DecreaseStep   uint32
+	BorrowStrategy BorrowStrategy
+	// how often idle objects are evicted in background, zero disables it.
+	EvictionInterval time.Duration
+	// called after every background eviction that destroyed objects.
+	EvictionReporter func(EvictionReport)
+
+	// create MinObjectCount objects before New returns, failing when more
+	// than PrefillMaxFailures constructions fail.
+	Prefill            bool
+	PrefillMaxFailures int
+	// goroutines creating objects for prefill and replenishment, at least 1.
+	PrefillParallelism int
+	// re-create objects in background whenever the pool drops below
+	// MinObjectCount.
+	Replenish bool
+
+	Destructor Destructor
+	// optional, objects with the same id are rejected as duplicates. Ids
+	// default to the identity of the object holder.
+	IdExtractor IdExtractor
+
+	// objects older than MaxLifetime minus a random part of
+	// MaxLifetimeJitter are retired once idle, zero means no limit.
+	MaxLifetime       time.Duration
+	MaxLifetimeJitter time.Duration
+
+	// objects lent MaxUseCount times are retired on return, zero means no
+	// limit.
+	MaxUseCount uint64
+
+	// objects borrowed longer than LeakDetectionThreshold are reported
+	// once to LeakReporter, or logged if it is nil. Zero disables leak
+	// detection and recording of borrower stacks.
+	LeakDetectionThreshold time.Duration
+	LeakReporter           func(LeakReport)
+
+	// bounds every construction, zero means no limit. A plain Constructor
+	// is then run in its own goroutine, the borrower stops waiting for it on
+	// timeout.
+	ConstructTimeout time.Duration
+
+	// optional health checks, an object failing one is destroyed. Borrow
+	// runs before an idle object is lent, Return when it comes back and
+	// Idle on every eviction pass.
+	BorrowValidator Validator
+	ReturnValidator Validator
+	IdleValidator   Validator
+
+	// waiting time worth one priority level of GetObjectWithPriority(), it
+	// keeps low priority waiters from starving. Zero serves strictly by
+	// priority.
+	PriorityAging time.Duration
+
+	// optional, notified of object lifecycle events.
+	Listener Listener
+
+	// optional, the pool is silent without Logger. Name is added to every
+	// record, constructions, destructions and borrows slower than
+	// SlowThreshold are logged, zero disables it.
+	Logger        *slog.Logger
+	Name          string
+	SlowThreshold time.Duration
+}
+
+// Validator reports why object is no longer fit for use.
+type Validator func(interface{}) error
+
+type Option func(*Config)
+
+func WithMinObjects(min_object uint32) Option {
+	return func(c *Config) {
+		c.MinObjectCount = min_object
+	}
+}
+
+func WithMaxObjects(max_object uint32) Option {
+	return func(c *Config) {
+		c.MaxObjectCount = max_object
+	}
+}
+
+func WithIdleTimeout(idle_time time.Duration) Option {
+	return func(c *Config) {
+		c.IdleTime = idle_time
+	}
+}
+
+func WithDecreaseStep(step uint32) Option {
+	return func(c *Config) {
+		c.DecreaseStep = step
+	}
+}
+
+func WithBorrowStrategy(strategy BorrowStrategy) Option {
+	return func(c *Config) {
+		c.BorrowStrategy = strategy
+	}
+}
+
+func WithEvictionInterval(interval time.Duration) Option {
+	return func(c *Config) {
+		c.EvictionInterval = interval
+	}
+}
+
+func WithEvictionReporter(reporter func(EvictionReport)) Option {
+	return func(c *Config) {
+		c.EvictionReporter = reporter
+	}
+}
+
+func WithPrefill(parallelism int, max_failures int) Option {
+	return func(c *Config) {
+		c.Prefill = true
+		c.PrefillParallelism = parallelism
+		c.PrefillMaxFailures = max_failures
+	}
+}
+
+func WithReplenish(replenish bool) Option {
+	return func(c *Config) {
+		c.Replenish = replenish
+	}
+}
+
+func WithMaxLifetime(lifetime time.Duration, jitter time.Duration) Option {
+	return func(c *Config) {
+		c.MaxLifetime = lifetime
+		c.MaxLifetimeJitter = jitter
+	}
+}
+
+func WithMaxUseCount(max_use uint64) Option {
+	return func(c *Config) {
+		c.MaxUseCount = max_use
+	}
+}
+
+func WithConstructTimeout(timeout time.Duration) Option {
+	return func(c *Config) {
+		c.ConstructTimeout = timeout
+	}
+}
+
+func WithLeakDetection(threshold time.Duration, reporter func(LeakReport)) Option {
+	return func(c *Config) {
+		c.LeakDetectionThreshold = threshold
+		c.LeakReporter = reporter
+	}
+}
+
+func WithBorrowValidator(validator Validator) Option {
+	return func(c *Config) {
+		c.BorrowValidator = validator
+	}
+}
+
+func WithReturnValidator(validator Validator) Option {
+	return func(c *Config) {
+		c.ReturnValidator = validator
+	}
+}
+
+func WithIdleValidator(validator Validator) Option {
+	return func(c *Config) {
+		c.IdleValidator = validator
+	}
+}
+
+func WithPriorityAging(aging time.Duration) Option {
+	return func(c *Config) {
+		c.PriorityAging = aging
+	}
+}
+
+func WithListener(listener Listener) Option {
+	return func(c *Config) {
+		c.Listener = listener
+	}
+}
+
+func WithLogger(logger *slog.Logger, name string) Option {
+	return func(c *Config) {
+		c.Logger = logger
+		c.Name = name
+	}
+}
+
+func WithSlowThreshold(threshold time.Duration) Option {
+	return func(c *Config) {
+		c.SlowThreshold = threshold
+	}
+}
+
+func WithDestructor(destructor Destructor) Option {
+	return func(c *Config) {
+		c.Destructor = destructor
+	}
+}
+
+func WithIdExtractor(idExtractor IdExtractor) Option {
+	return func(c *Config) {
+		c.IdExtractor = idExtractor
+	}
+}
+
+// DefaultConfig is the config New starts from before applying options.
+func DefaultConfig() Config {
+	return Config{
+		MinObjectCount:   0,
+		MaxObjectCount:   UnlimitedObjectCount,
+		IdleTime:         defaultIdleTime,
+		EvictionInterval: defaultEvictionInterval,
+		PriorityAging:    defaultPriorityAging,
+		Destructor:       func(interface{}) {},
+	}
+}
+
+func (c Config) Validate() error {
+	if c.MaxObjectCount != UnlimitedObjectCount && c.MinObjectCount > c.MaxObjectCount {
+		return fmt.Errorf("min_object should lower or equal to max_object, max_object:%d, min_object:%d", c.MaxObjectCount, c.MinObjectCount)
+	}
+
+	if c.IdleTime < 0 {
+		return fmt.Errorf("idle_time should not be negative, idle_time:%s", c.IdleTime)
+	}
+
+	if c.BorrowStrategy < BorrowLIFO || c.BorrowStrategy > BorrowRandom {
+		return fmt.Errorf("unknown borrow strategy, borrow_strategy:%s", c.BorrowStrategy)
+	}
+
+	if c.EvictionInterval < 0 {
+		return fmt.Errorf("eviction_interval should not be negative, eviction_interval:%s", c.EvictionInterval)
+	}
+
+	if c.PrefillParallelism < 0 || c.PrefillMaxFailures < 0 {
+		return fmt.Errorf("prefill settings should not be negative, parallelism:%d, max_failures:%d", c.PrefillParallelism, c.PrefillMaxFailures)
+	}
+
+	if c.MaxLifetime < 0 || c.MaxLifetimeJitter < 0 || (c.MaxLifetimeJitter > 0 && c.MaxLifetimeJitter >= c.MaxLifetime) {
+		return fmt.Errorf("max_lifetime_jitter should be lower than max_lifetime, max_lifetime:%s, max_lifetime_jitter:%s", c.MaxLifetime, c.MaxLifetimeJitter)
+	}
+
+	if c.ConstructTimeout < 0 {
+		return fmt.Errorf("construct_timeout should not be negative, construct_timeout:%s", c.ConstructTimeout)
+	}
+
+	if c.PriorityAging < 0 {
+		return fmt.Errorf("priority_aging should not be negative, priority_aging:%s", c.PriorityAging)
+	}
+
+	if c.SlowThreshold < 0 {
+		return fmt.Errorf("slow_threshold should not be negative, slow_threshold:%s", c.SlowThreshold)
+	}
+
+	if c.LeakDetectionThreshold < 0 {
+		return fmt.Errorf("leak_detection_threshold should not be negative, leak_detection_threshold:%s", c.LeakDetectionThreshold)
+	}
+
+	if c.Destructor == nil {
+		return errors.New("need parameter destructor")
+	}
+
+	return nil
+}
+
+func (c Config) String() string {
+	max_object := "unlimited"
+	if c.MaxObjectCount != UnlimitedObjectCount {
+		max_object = fmt.Sprint(c.MaxObjectCount)
+	}
+	return fmt.Sprintf("min_object:%d, max_object:%s, idle_time:%s, decrease_step:%d, borrow_strategy:%s, eviction_interval:%s",
+		c.MinObjectCount, max_object, c.IdleTime, c.decreaseStep(), c.BorrowStrategy, c.EvictionInterval)
+}
+
+func (c Config) decreaseStep() uint32 {
+	if c.DecreaseStep != 0 {
+		return c.DecreaseStep
+	}
+
+	decreaseStep := uint32(0)
+	if c.MaxObjectCount != UnlimitedObjectCount {
+		decreaseStep = c.MaxObjectCount - c.MinObjectCount
+	}
+	decreaseStep = decreaseStep / 20
+	if decreaseStep < 10 {
+		decreaseStep = 10
+	}
+	return decreaseStep
+}
+
+// expireTime spreads expiry of objects created at the same time.
+func (c Config) expireTime(createTime time.Time) time.Time {
+	if c.MaxLifetime <= 0 {
+		return time.Time{}
+	}
+	lifetime := c.MaxLifetime
+	if c.MaxLifetimeJitter > 0 {
+		lifetime -= time.Duration(rand.Int63n(int64(c.MaxLifetimeJitter)))
+	}
+	return createTime.Add(lifetime)
+}
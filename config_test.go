@@ -0,0 +1,107 @@
+package ObjectPool
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewOptions_Default(t *testing.T) {
+	pool, err := New(test_object_constructor)
+	if err != nil {
+		t.Fatalf("New() create pool failed, err:%v", err)
+	}
+	defer pool.Close()
+
+	config := pool.Config()
+	if config.MaxObjectCount != UnlimitedObjectCount || config.MinObjectCount != 0 || config.IdleTime != defaultIdleTime {
+		t.Fatalf("invalid default config:%s", config)
+	}
+
+	// unlimited mode never reports exhaustion.
+	object_count := 100
+	for idx := 0; idx < object_count; idx += 1 {
+		get_object_and_check(t, pool)
+	}
+	if int(pool.GetObjectCount()) != object_count {
+		t.Fatalf("GetObjectCount() expect:%d, get:%d", object_count, pool.GetObjectCount())
+	}
+}
+
+func TestNewOptions_OK(t *testing.T) {
+	pool, err := New(test_object_constructor,
+			WithMinObjects(1),
+			WithMaxObjects(2),
+			WithIdleTimeout(idle_2s),
+			WithDecreaseStep(3),
+			WithDestructor(test_object_destructor),
+			WithIdExtractor(test_object_id_extractor))
+	if err != nil {
+		t.Fatalf("New() create pool failed, err:%v", err)
+	}
+	defer pool.Close()
+
+	if pool.GetMinObjectCount() != 1 || pool.GetMaxObjectCount() != 2 || pool.GetIdleTime() != idle_2s {
+		t.Fatalf("options not applied, config:%s", pool.Config())
+	}
+	if pool.decreaseStep != 3 {
+		t.Fatalf("decrease step expect:%d, get:%d", 3, pool.decreaseStep)
+	}
+
+	get_object_and_check(t, pool)
+	get_object_and_check(t, pool)
+	if _, err := pool.GetObject(); err != ErrReachMaxLimit {
+		t.Fatalf("GetObject() should fail with ErrReachMaxLimit, err:%v", err)
+	}
+}
+
+func TestNewOptions_Invalid(t *testing.T) {
+	_, err := New(test_object_constructor, WithMinObjects(3), WithMaxObjects(2))
+	if err == nil {
+		t.Fatalf("New() should checking min is lower to max")
+	}
+
+	_, err = New(test_object_constructor, WithMinObjects(3), WithMaxObjects(UnlimitedObjectCount))
+	if err != nil {
+		t.Fatalf("min should not be checked against unlimited max, err:%v", err)
+	}
+
+	_, err = New(test_object_constructor, WithDestructor(nil))
+	if err == nil {
+		t.Fatalf("New() should checking destructor is not nil")
+	}
+
+	_, err = New(nil)
+	if err == nil {
+		t.Fatalf("New() should checking constructor is not nil")
+	}
+
+	_, err = New(test_object_constructor, WithIdleTimeout(-idle_2s))
+	if err == nil {
+		t.Fatalf("New() should checking idle time is not negative")
+	}
+}
+
+func TestConfig_DecreaseStep(t *testing.T) {
+	config := DefaultConfig()
+	if config.decreaseStep() != 10 {
+		t.Fatalf("unlimited decrease step expect:%d, get:%d", 10, config.decreaseStep())
+	}
+
+	config.MinObjectCount = uint_512
+	config.MaxObjectCount = uint_2048
+	if config.decreaseStep() != (uint_2048 - uint_512) / 20 {
+		t.Fatalf("decrease step expect:%d, get:%d", (uint_2048 - uint_512) / 20, config.decreaseStep())
+	}
+}
+
+func TestConfig_String(t *testing.T) {
+	config := DefaultConfig()
+	if !strings.Contains(config.String(), "max_object:unlimited") {
+		t.Fatalf("unlimited mode should be printed, get:%s", config)
+	}
+
+	config.MaxObjectCount = uint_1024
+	if !strings.Contains(config.String(), "max_object:1024") {
+		t.Fatalf("max object should be printed, get:%s", config)
+	}
+}
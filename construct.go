@@ -0,0 +1,76 @@
+package ObjectPool
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// ConstructTimeoutError is returned when an object was not constructed
+// within Config.ConstructTimeout.
+type ConstructTimeoutError struct {
+	Timeout time.Duration
+	Err     error
+}
+
+func (e *ConstructTimeoutError) Error() string {
+	return fmt.Sprintf("construct object timeout, timeout:%s, error:%s", e.Timeout, e.Err)
+}
+
+func (e *ConstructTimeoutError) Unwrap() error {
+	return e.Err
+}
+
+type constructResult struct {
+	object interface{}
+	err    error
+}
+
+// contextConstructor adapts a plain Constructor. With a construct timeout it
+// runs in its own goroutine, so the borrower can give up on it, and an object
+// it returns too late is destructed.
+func contextConstructor(constructor Constructor, config Config) ContextConstructor {
+	if constructor == nil {
+		return nil
+	}
+	if config.ConstructTimeout <= 0 {
+		return func(context.Context) (interface{}, error) {
+			return constructor()
+		}
+	}
+	destructor := config.Destructor
+	return func(ctx context.Context) (interface{}, error) {
+		result := make(chan constructResult, 1)
+		go func() {
+			object, err := constructor()
+			result <- constructResult{object: object, err: err}
+		}()
+		select {
+		case r := <-result:
+			return r.object, r.err
+		case <-ctx.Done():
+			go func() {
+				if r := <-result; r.err == nil {
+					destructor(r.object)
+				}
+			}()
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// construct calls the constructor with ctx limited by ConstructTimeout.
+func (p *BasicPool) construct(ctx context.Context) (interface{}, error) {
+	if p.config.ConstructTimeout <= 0 {
+		return p.constructor(ctx)
+	}
+	construct_ctx, cancel := context.WithTimeout(ctx, p.config.ConstructTimeout)
+	defer cancel()
+
+	object, err := p.constructor(construct_ctx)
+	// timed out, unless the borrower gave up first.
+	if err != nil && ctx.Err() == nil && construct_ctx.Err() == context.DeadlineExceeded {
+		return nil, &ConstructTimeoutError{Timeout: p.config.ConstructTimeout, Err: err}
+	}
+	return object, err
+}
@@ -0,0 +1,100 @@
+package ObjectPool
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func blocking_context_constructor(ctx context.Context) (interface{}, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestConstruct_ContextCanceled(t *testing.T) {
+	pool, err := NewWithContextConstructor(blocking_context_constructor)
+	if err != nil {
+		t.Fatalf("NewWithContextConstructor() create pool failed, err:%v", err)
+	}
+	defer pool.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), idle_50ms)
+	defer cancel()
+	_, err = pool.GetObjectContext(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("constructor should get the borrower context, err:%v", err)
+	}
+	var timeout_err *ConstructTimeoutError
+	if errors.As(err, &timeout_err) {
+		t.Fatalf("borrower timeout is not a construct timeout, err:%v", err)
+	}
+	if pool.GetObjectCount() != 0 {
+		t.Fatalf("object count mismatch, expect:%d, get:%d", 0, pool.GetObjectCount())
+	}
+}
+
+func TestConstruct_Timeout(t *testing.T) {
+	pool, err := NewWithContextConstructor(blocking_context_constructor, WithConstructTimeout(idle_50ms))
+	if err != nil {
+		t.Fatalf("NewWithContextConstructor() create pool failed, err:%v", err)
+	}
+	defer pool.Close()
+
+	_, err = pool.GetObject()
+	var timeout_err *ConstructTimeoutError
+	if !errors.As(err, &timeout_err) || timeout_err.Timeout != idle_50ms {
+		t.Fatalf("construct timeout error expected, err:%v", err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("construct timeout should wrap the context error, err:%v", err)
+	}
+	if stats := pool.Stats(); stats.ConstructFailureCount != 1 || stats.ObjectCount != 0 {
+		t.Fatalf("stats mismatch, stats:%+v", stats)
+	}
+}
+
+func TestConstruct_PlainConstructorTimeout(t *testing.T) {
+	release := make(chan struct{})
+	destructed := int32(0)
+	pool, err := New(func() (interface{}, error) {
+		<-release
+		return test_object_constructor()
+	}, WithConstructTimeout(idle_50ms), WithDestructor(func(interface{}) {
+		atomic.AddInt32(&destructed, 1)
+	}))
+	if err != nil {
+		t.Fatalf("New() create pool failed, err:%v", err)
+	}
+	defer pool.Close()
+
+	start := time.Now()
+	_, err = pool.GetObject()
+	var timeout_err *ConstructTimeoutError
+	if !errors.As(err, &timeout_err) {
+		t.Fatalf("construct timeout error expected, err:%v", err)
+	}
+	if time.Since(start) > idle_2s {
+		t.Fatalf("hung constructor should not block the borrower, elapsed:%s", time.Since(start))
+	}
+
+	// the late object is not pooled but destructed.
+	close(release)
+	deadline := time.Now().Add(idle_2s)
+	for atomic.LoadInt32(&destructed) != 1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("late object should be destructed")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if pool.GetObjectCount() != 0 {
+		t.Fatalf("object count mismatch, expect:%d, get:%d", 0, pool.GetObjectCount())
+	}
+}
+
+func TestConfig_ConstructTimeout(t *testing.T) {
+	if _, err := New(test_object_constructor, WithConstructTimeout(-time.Second)); err == nil {
+		t.Fatalf("New() should checking construct timeout is not negative")
+	}
+}
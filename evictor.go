@@ -0,0 +1,220 @@
+package ObjectPool
+
+import (
+	"sort"
+	"time"
+)
+
+// EvictionReport describes one pass of idle object eviction.
+type EvictionReport struct {
+	Time       time.Time
+	EvictedIds []string
+	// idle objects destroyed because they outlived MaxLifetime.
+	ExpiredIds []string
+	// idle objects destroyed because IdleValidator failed.
+	InvalidIds []string
+	// what is left in the pool after the pass.
+	IdleObjectCount uint32
+	ObjectCount     uint32
+}
+
+// EvictIdleObjects destroys objects idle longer than idleTime, oldest first,
+// but never shrinks the pool below minObjectCount. Expired idle objects are
+// destroyed regardless of minObjectCount, the remaining ones are then checked
+// by IdleValidator.
+func (p *BasicPool) EvictIdleObjects() EvictionReport {
+	report := EvictionReport{Time: time.Now()}
+
+	p.mutex.Lock()
+	if p.closed {
+		p.mutex.Unlock()
+		return report
+	}
+	// lock-free returns skip the eviction of ReturnObject(), it is done here.
+	p.flushParkedLocked()
+
+	evictable := len(p.idlePool) + len(p.activePool) - int(p.minObjectCount)
+	count := 0
+	for ; count < evictable && count < len(p.idlePool); count += 1 {
+		if p.idlePool[count].lastUse().Add(p.idleTime).After(report.Time) {
+			break
+		}
+	}
+
+	evicted := make([]*ObjectHolder, count)
+	copy(evicted, p.idlePool[:count])
+	copy(p.idlePool, p.idlePool[count:])
+	p.idlePool = p.idlePool[:len(p.idlePool)-count]
+
+	var expired []*ObjectHolder
+	alive := p.idlePool[:0]
+	for _, object := range p.idlePool {
+		if object.expired(report.Time) {
+			expired = append(expired, object)
+		} else {
+			alive = append(alive, object)
+		}
+	}
+	p.idlePool = alive
+	p.syncLimiterLocked()
+	p.destroyedCount += uint64(len(evicted) + len(expired))
+	p.evictedCount += uint64(len(evicted) + len(expired))
+	p.mutex.Unlock()
+
+	for _, object := range evicted {
+		report.EvictedIds = append(report.EvictedIds, object.id)
+		p.evictObject(object, evictIdleTimeout)
+	}
+	for _, object := range expired {
+		report.ExpiredIds = append(report.ExpiredIds, object.id)
+		p.evictObject(object, evictExpired)
+	}
+
+	if p.config.IdleValidator != nil {
+		report.InvalidIds = p.validateIdleObjects()
+	}
+
+	p.mutex.Lock()
+	report.IdleObjectCount, _ = p.countObjectsLocked()
+	report.ObjectCount = uint32(len(p.idlePool) + len(p.activePool))
+	// also retries replenishment that failed earlier.
+	p.signalReplenishLocked()
+	p.mutex.Unlock()
+
+	return report
+}
+
+// validateIdleObjects checks idle objects one at a time, so at most one of
+// them is unavailable to borrowers while its validator runs.
+func (p *BasicPool) validateIdleObjects() []string {
+	p.mutex.Lock()
+	candidates := make([]*ObjectHolder, len(p.idlePool))
+	copy(candidates, p.idlePool)
+	p.mutex.Unlock()
+
+	var invalidIds []string
+	for _, object := range candidates {
+		p.mutex.Lock()
+		if p.closed {
+			p.mutex.Unlock()
+			break
+		}
+		// borrowed in the meantime.
+		if !p.removeIdleLocked(object) {
+			p.mutex.Unlock()
+			continue
+		}
+		p.activePool[object] = true
+		p.mutex.Unlock()
+
+		err := p.config.IdleValidator(object.object)
+		p.mutex.Lock()
+		// InvalidateByID() may be called meanwhile.
+		invalidated := object.invalidated.Load()
+		info := object.info()
+		p.mutex.Unlock()
+		if err != nil || invalidated {
+			invalidIds = append(invalidIds, object.id)
+			p.mutex.Lock()
+			p.evictedCount += 1
+			p.mutex.Unlock()
+			p.logEvict(object.id, evictInvalid)
+			p.listener.OnEvict(info)
+			p.destroyActiveObject(object)
+			continue
+		}
+
+		p.mutex.Lock()
+		if p.closed {
+			p.returnClosedLocked(object)
+			break
+		}
+		delete(p.activePool, object)
+		p.insertIdleLocked(object)
+		p.mutex.Unlock()
+	}
+	return invalidIds
+}
+
+func (p *BasicPool) removeIdleLocked(object *ObjectHolder) bool {
+	for idx, idle := range p.idlePool {
+		if idle == object {
+			p.idlePool = append(p.idlePool[:idx], p.idlePool[idx+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// insertIdleLocked puts an object back keeping idlePool ordered by
+// lastUseTime, unless a waiter takes it.
+func (p *BasicPool) insertIdleLocked(object *ObjectHolder) {
+	if p.waitQueue.Len() > 0 {
+		p.putIdleLocked(object)
+		return
+	}
+	idx := sort.Search(len(p.idlePool), func(i int) bool {
+		return p.idlePool[i].lastUseTime.Load() > object.lastUseTime.Load()
+	})
+	p.idlePool = append(p.idlePool, nil)
+	copy(p.idlePool[idx+1:], p.idlePool[idx:])
+	p.idlePool[idx] = object
+}
+
+func (p *BasicPool) idleObjectEvictor(interval time.Duration) {
+	defer p.background.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			report := p.EvictIdleObjects()
+			destroyed := len(report.EvictedIds) + len(report.ExpiredIds) + len(report.InvalidIds)
+			if destroyed > 0 && p.config.EvictionReporter != nil {
+				p.config.EvictionReporter(report)
+			}
+		case <-p.stopSignal:
+			return
+		}
+	}
+}
+
+func (p *BasicPool) oldestIdleTime() (time.Time, bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if !p.closed {
+		p.flushParkedLocked()
+	}
+	if len(p.idlePool) == 0 {
+		return time.Time{}, false
+	}
+	return p.idlePool[0].lastUse(), true
+}
+
+// evictOldestIdle destroys the least recently used idle object regardless of
+// minObjectCount, a KeyedPool uses it to make room for other keys.
+func (p *BasicPool) evictOldestIdle() bool {
+	p.mutex.Lock()
+	if !p.closed {
+		p.flushParkedLocked()
+	}
+	if p.closed || len(p.idlePool) == 0 {
+		p.mutex.Unlock()
+		return false
+	}
+
+	object := p.idlePool[0]
+	copy(p.idlePool, p.idlePool[1:])
+	p.idlePool[len(p.idlePool)-1] = nil
+	p.idlePool = p.idlePool[:len(p.idlePool)-1]
+	p.destroyedCount += 1
+	p.evictedCount += 1
+	p.releaseSlotLocked()
+	p.mutex.Unlock()
+
+	p.evictObject(object, evictKeyedPool)
+	return true
+}
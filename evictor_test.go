@@ -0,0 +1,196 @@
+package ObjectPool
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEvict_RefreshLastUseTime(t *testing.T) {
+	pool, err := New(test_object_constructor,
+			WithIdleTimeout(idle_50ms),
+			WithEvictionInterval(0))
+	if err != nil {
+		t.Fatalf("New() create pool failed, err:%v", err)
+	}
+	defer pool.Close()
+
+	object_holder := get_object_and_check(t, pool)
+	// hold it longer than idle time, returning must count as a use.
+	time.Sleep(2 * idle_50ms)
+	pool.ReturnObject(object_holder)
+
+	report := pool.EvictIdleObjects()
+	if len(report.EvictedIds) != 0 || pool.GetIdleObjectCount() != 1 {
+		t.Fatalf("just returned object should not be evicted, evicted:%v, idle:%d",
+			report.EvictedIds, pool.GetIdleObjectCount())
+	}
+
+	time.Sleep(2 * idle_50ms)
+	report = pool.EvictIdleObjects()
+	if len(report.EvictedIds) != 1 || report.ObjectCount != 0 || pool.GetObjectCount() != 0 {
+		t.Fatalf("idle object should be evicted, evicted:%v, object_count:%d",
+			report.EvictedIds, pool.GetObjectCount())
+	}
+}
+
+func TestEvict_Background(t *testing.T) {
+	reports := make(chan EvictionReport, 10)
+	pool, err := New(test_object_constructor,
+			WithMinObjects(1),
+			WithIdleTimeout(idle_50ms),
+			WithEvictionInterval(10 * time.Millisecond),
+			WithIdExtractor(test_object_id_extractor),
+			WithEvictionReporter(func(report EvictionReport) {
+				reports <- report
+			}))
+	if err != nil {
+		t.Fatalf("New() create pool failed, err:%v", err)
+	}
+	defer pool.Close()
+
+	object_count := 3
+	object_holders := make([]*ObjectHolder, object_count)
+	for idx := 0; idx < object_count; idx += 1 {
+		object_holders[idx] = get_object_and_check(t, pool)
+	}
+	for _, object_holder := range object_holders {
+		pool.ReturnObject(object_holder)
+	}
+
+	evicted := 0
+	deadline := time.After(idle_2s)
+	for evicted < object_count - 1 {
+		select {
+		case report := <-reports:
+			evicted += len(report.EvictedIds)
+		case <-deadline:
+			t.Fatalf("evictor should evict idle objects, evicted:%d", evicted)
+		}
+	}
+
+	// never below min object count.
+	time.Sleep(2 * idle_50ms)
+	report := pool.EvictIdleObjects()
+	if report.IdleObjectCount != 1 || report.ObjectCount != 1 {
+		t.Fatalf("evictor should keep min objects, idle:%d, total:%d",
+			report.IdleObjectCount, report.ObjectCount)
+	}
+}
+
+func TestEvict_IdleValidator(t *testing.T) {
+	fixture := new_validator_fixture()
+	pool, err := New(test_object_constructor,
+			WithEvictionInterval(0),
+			WithDestructor(fixture.destruct),
+			WithIdExtractor(test_object_id_extractor),
+			WithIdleValidator(fixture.validate))
+	if err != nil {
+		t.Fatalf("New() create pool failed, err:%v", err)
+	}
+	defer pool.Close()
+
+	object_count := 3
+	object_holders := make([]*ObjectHolder, object_count)
+	for idx := 0; idx < object_count; idx += 1 {
+		object_holders[idx] = get_object_and_check(t, pool)
+	}
+	for _, object_holder := range object_holders {
+		pool.ReturnObject(object_holder)
+	}
+	fixture.set_broken(object_holders[1].ExtractObject())
+
+	report := pool.EvictIdleObjects()
+	broken_id := test_object_id_extractor(object_holders[1].ExtractObject())
+	if len(report.InvalidIds) != 1 || report.InvalidIds[0] != broken_id {
+		t.Fatalf("broken idle object should be reported, expect:%s, get:%v", broken_id, report.InvalidIds)
+	}
+	if report.IdleObjectCount != 2 || fixture.destructed_count() != 1 {
+		t.Fatalf("broken idle object should be destructed, idle:%d, destructed:%d",
+			report.IdleObjectCount, fixture.destructed_count())
+	}
+
+	// valid objects keep their idle order.
+	if pool.idlePool[0] != object_holders[0] || pool.idlePool[1] != object_holders[2] {
+		t.Fatalf("idle order should be kept after validation")
+	}
+}
+
+func TestMaxLifetime_Return(t *testing.T) {
+	pool, err := New(test_object_constructor,
+			WithEvictionInterval(0),
+			WithMaxLifetime(idle_50ms, 0))
+	if err != nil {
+		t.Fatalf("New() create pool failed, err:%v", err)
+	}
+	defer pool.Close()
+
+	object_holder := get_object_and_check(t, pool)
+	// never retired while borrowed.
+	time.Sleep(2 * idle_50ms)
+	if !object_holder.IsUsable() || pool.GetObjectCount() != 1 {
+		t.Fatalf("borrowed object should not be retired")
+	}
+
+	pool.ReturnObject(object_holder)
+	if pool.GetObjectCount() != 0 {
+		t.Fatalf("expired object should be retired on return, object_count:%d", pool.GetObjectCount())
+	}
+}
+
+func TestMaxLifetime_Idle(t *testing.T) {
+	pool, err := New(test_object_constructor,
+			WithMinObjects(2),
+			WithEvictionInterval(0),
+			WithIdExtractor(test_object_id_extractor),
+			WithMaxLifetime(idle_50ms, 0))
+	if err != nil {
+		t.Fatalf("New() create pool failed, err:%v", err)
+	}
+	defer pool.Close()
+
+	first := get_object_and_check(t, pool)
+	second := get_object_and_check(t, pool)
+	pool.ReturnObject(first)
+	pool.ReturnObject(second)
+	time.Sleep(2 * idle_50ms)
+
+	// expired idle objects are not lent.
+	object_holder := get_object_and_check(t, pool)
+	if object_holder == first || object_holder == second {
+		t.Fatalf("expired idle object should not be lent")
+	}
+	pool.ReturnObject(object_holder)
+
+	// min object count does not protect expired objects.
+	time.Sleep(2 * idle_50ms)
+	report := pool.EvictIdleObjects()
+	if len(report.ExpiredIds) != 1 || report.ObjectCount != 0 {
+		t.Fatalf("expired idle object should be evicted, expired:%v, object_count:%d",
+			report.ExpiredIds, report.ObjectCount)
+	}
+}
+
+func TestMaxLifetime_Jitter(t *testing.T) {
+	config := DefaultConfig()
+	config.MaxLifetime = 10 * time.Second
+	config.MaxLifetimeJitter = 5 * time.Second
+
+	now := time.Now()
+	expire_times := make(map[time.Time]bool)
+	for idx := 0; idx < 100; idx += 1 {
+		expire_time := config.expireTime(now)
+		lifetime := expire_time.Sub(now)
+		if lifetime <= config.MaxLifetime - config.MaxLifetimeJitter || lifetime > config.MaxLifetime {
+			t.Fatalf("lifetime out of range, lifetime:%s", lifetime)
+		}
+		expire_times[expire_time] = true
+	}
+	if len(expire_times) < 2 {
+		t.Fatalf("jitter should spread expire time")
+	}
+
+	config.MaxLifetimeJitter = config.MaxLifetime
+	if config.Validate() == nil {
+		t.Fatalf("jitter should be lower than lifetime")
+	}
+}
@@ -0,0 +1,54 @@
+package ObjectPool
+
+import (
+	"expvar"
+	"fmt"
+	"sync"
+)
+
+// expvarMutex makes checking and publishing a name atomic, expvar.Publish
+// panics on duplicates.
+var expvarMutex sync.Mutex
+
+type expvarStats struct {
+	Idle      uint32 `json:"idle"`
+	Active    uint32 `json:"active"`
+	Max       uint32 `json:"max"`
+	Min       uint32 `json:"min"`
+	Created   uint64 `json:"created"`
+	Destroyed uint64 `json:"destroyed"`
+	Evictions uint64 `json:"evictions"`
+}
+
+// PublishExpvar registers the pool counters under name in /debug/vars, a
+// max of 0 means unlimited.
+func (p *BasicPool) PublishExpvar(name string) error {
+	return publishExpvar(name, p)
+}
+
+func (p *Pool[T]) PublishExpvar(name string) error {
+	return publishExpvar(name, p)
+}
+
+func publishExpvar(name string, pool StatsProvider) error {
+	expvarMutex.Lock()
+	defer expvarMutex.Unlock()
+
+	if expvar.Get(name) != nil {
+		return fmt.Errorf("expvar is already published, name:%s", name)
+	}
+
+	expvar.Publish(name, expvar.Func(func() interface{} {
+		stats := pool.Stats()
+		return expvarStats{
+			Idle:      stats.IdleObjectCount,
+			Active:    stats.ActiveObjectCount,
+			Max:       stats.MaxObjectCount,
+			Min:       stats.MinObjectCount,
+			Created:   stats.CreatedCount,
+			Destroyed: stats.DestroyedCount,
+			Evictions: stats.EvictedCount,
+		}
+	}))
+	return nil
+}
@@ -0,0 +1,49 @@
+package ObjectPool
+
+import (
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"sync/atomic"
+	"testing"
+)
+
+// expvar names can not be unpublished, every run publishes a new one.
+var expvar_name_seq = int32(0)
+
+func unique_expvar_name(t *testing.T) string {
+	return fmt.Sprintf("%s_%d", t.Name(), atomic.AddInt32(&expvar_name_seq, 1))
+}
+
+func TestPublishExpvar(t *testing.T) {
+	pool := new_test_object_pool(t, 1, 10)
+	defer pool.Close()
+
+	name := unique_expvar_name(t)
+	err := pool.PublishExpvar(name)
+	if err != nil {
+		t.Fatalf("PublishExpvar() failed, err:%s", err)
+	}
+	if err := pool.PublishExpvar(name); err == nil {
+		t.Fatalf("PublishExpvar() should refuse a published name")
+	}
+
+	return_in_order(t, pool, 2)
+	get_object_and_check(t, pool)
+
+	var published map[string]uint64
+	if err := json.Unmarshal([]byte(expvar.Get(name).String()), &published); err != nil {
+		t.Fatalf("invalid expvar json, err:%s", err)
+	}
+
+	expects := map[string]uint64{
+		"idle": 1, "active": 1, "max": 10, "min": 1,
+		"created": 2, "destroyed": 0, "evictions": 0,
+	}
+	for key, expect := range expects {
+		get, has := published[key]
+		if !has || get != expect {
+			t.Errorf("expvar %s expect:%d, get:%d, has:%v", key, expect, get, has)
+		}
+	}
+}
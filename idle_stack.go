@@ -0,0 +1,222 @@
+package ObjectPool
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// idleStack is a lock-free LIFO of idle objects, the fast path of borrow and
+// return. It is a Treiber stack over slot indexes rather than pointers, the
+// head packs the top slot index+1 with a version bumped on every change, so
+// a pop racing with a pop and push of the same object (ABA) fails its CAS.
+// Push and pop do not allocate.
+type idleStack struct {
+	head atomic.Uint64
+	size atomic.Int64
+	// copy on write, objects look up the slot below them here.
+	slots atomic.Pointer[[]atomic.Pointer[ObjectHolder]]
+
+	// guards slot assignment, taken only when objects are created or
+	// destroyed.
+	mutex sync.Mutex
+	free  []uint32
+}
+
+func packHead(slot uint32, version uint32) uint64 {
+	return uint64(version)<<32 | uint64(slot)
+}
+
+func (s *idleStack) push(object *ObjectHolder) {
+	for {
+		head := s.head.Load()
+		object.next.Store(uint32(head))
+		if s.head.CompareAndSwap(head, packHead(object.slot, uint32(head>>32)+1)) {
+			s.size.Add(1)
+			return
+		}
+	}
+}
+
+func (s *idleStack) pop() *ObjectHolder {
+	for {
+		head := s.head.Load()
+		top := uint32(head)
+		if top == 0 {
+			return nil
+		}
+		// the object may be popped and pushed again meanwhile, then next is
+		// stale and the version makes the CAS fail.
+		object := (*s.slots.Load())[top-1].Load()
+		if object == nil {
+			continue
+		}
+		next := object.next.Load()
+		if s.head.CompareAndSwap(head, packHead(next, uint32(head>>32)+1)) {
+			s.size.Add(-1)
+			return object
+		}
+	}
+}
+
+func (s *idleStack) len() int {
+	size := s.size.Load()
+	if size < 0 {
+		return 0
+	}
+	return int(size)
+}
+
+// register gives object a slot, it must be called before the first push.
+func (s *idleStack) register(object *ObjectHolder) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var slots []atomic.Pointer[ObjectHolder]
+	if current := s.slots.Load(); current != nil {
+		slots = *current
+	}
+	if len(s.free) == 0 {
+		if len(slots) < cap(slots) {
+			// readers never look past the length they loaded.
+			slots = slots[:len(slots)+1]
+		} else {
+			grown := make([]atomic.Pointer[ObjectHolder], len(slots)+1, 2*len(slots)+1)
+			for idx := range slots {
+				grown[idx].Store(slots[idx].Load())
+			}
+			slots = grown
+		}
+		s.slots.Store(&slots)
+		s.free = append(s.free, uint32(len(slots)))
+	}
+	object.slot = s.free[len(s.free)-1]
+	s.free = s.free[:len(s.free)-1]
+	slots[object.slot-1].Store(object)
+}
+
+// release frees the slot of a destroyed object, it must not be on the
+// stack.
+func (s *idleStack) release(object *ObjectHolder) {
+	if object.slot == 0 {
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	(*s.slots.Load())[object.slot-1].Store(nil)
+	s.free = append(s.free, object.slot)
+	object.slot = 0
+}
+
+func (p *BasicPool) popParked() *ObjectHolder {
+	object := p.idleStack.pop()
+	if object != nil {
+		object.state.Store(holderIdle)
+	}
+	return object
+}
+
+// borrowParked is the lock-free GetObject, it takes the object parked last.
+func (p *BasicPool) borrowParked() *ObjectHolder {
+	if !p.fastPath || p.waiters.Load() > 0 || p.closing.Load() {
+		return nil
+	}
+	for {
+		object := p.popParked()
+		if object == nil {
+			return nil
+		}
+		if object.expired(time.Now()) || object.invalidated.Load() ||
+			(p.config.BorrowValidator != nil && p.config.BorrowValidator(object.object) != nil) {
+			p.destroyActiveObject(object)
+			continue
+		}
+		object.useCount.Add(1)
+		return object
+	}
+}
+
+// returnParked is the lock-free ReturnObject, it parks a reusable object on
+// idleStack. It reports false if the return needs the mutex: the pool is
+// closing, shrinking or has waiters, or object is not reusable or not lent
+// by the pool.
+func (p *BasicPool) returnParked(object *ObjectHolder) bool {
+	if !p.fastPath || object.owner != p || p.closing.Load() || p.overMax.Load() || p.waiters.Load() > 0 {
+		return false
+	}
+	now := time.Now()
+	if !p.reusable(object, now) {
+		return false
+	}
+	info := object.info()
+	if !object.state.CompareAndSwap(holderBorrowed, holderReturning) {
+		return false
+	}
+	info.Borrowed = false
+
+	p.holdHistogram.observe(now.Sub(object.borrowedAt()))
+	object.borrowTime.Store(0)
+	object.lastUseTime.Store(now.UnixNano())
+	object.state.Store(holderParked)
+	p.idleStack.push(object)
+
+	// a waiter or Shutdown() checked idleStack before the push, they rely on
+	// the returner to move it.
+	if p.waiters.Load() > 0 || p.closing.Load() {
+		p.mutex.Lock()
+		closed := p.flushParkedLocked()
+		p.mutex.Unlock()
+		for _, object := range closed {
+			p.destroyObject(object)
+		}
+	}
+	if p.limiter != nil {
+		p.limiter.broadcast()
+	}
+	p.listener.OnReturn(info)
+	return true
+}
+
+// flushParkedLocked moves parked objects to idlePool or hands them to
+// waiters. In a closed pool it returns them to be destructed instead.
+func (p *BasicPool) flushParkedLocked() []*ObjectHolder {
+	var closed []*ObjectHolder
+	for {
+		object := p.popParked()
+		if object == nil {
+			break
+		}
+		if !p.closed {
+			delete(p.activePool, object)
+			p.insertIdleLocked(object)
+			continue
+		}
+		if _, has := p.activePool[object]; has {
+			delete(p.activePool, object)
+			p.destroyedCount += 1
+			closed = append(closed, object)
+		}
+	}
+	if len(closed) > 0 {
+		p.checkDrainedLocked()
+	}
+	return closed
+}
+
+// countObjectsLocked splits the objects into idle and active ones, parked
+// objects are idle though they stay in activePool.
+func (p *BasicPool) countObjectsLocked() (uint32, uint32) {
+	parked := p.idleStack.len()
+	if parked > len(p.activePool) {
+		parked = len(p.activePool)
+	}
+	return uint32(len(p.idlePool) + parked), uint32(len(p.activePool) - parked)
+}
+
+// updateOverMaxLocked sends returns to the slow path while the pool holds
+// more objects than allowed.
+func (p *BasicPool) updateOverMaxLocked() {
+	p.overMax.Store(p.maxObjectCount != UnlimitedObjectCount &&
+		len(p.idlePool)+len(p.activePool) > int(p.maxObjectCount))
+}
@@ -0,0 +1,197 @@
+package ObjectPool
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestIdleStack_Concurrent(t *testing.T) {
+	var stack idleStack
+	objects := make([]*ObjectHolder, 64)
+	for idx := range objects {
+		objects[idx] = NewObjectHolder(&test_object{id: int32(idx)})
+		stack.register(objects[idx])
+		stack.push(objects[idx])
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i += 1 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10000; j += 1 {
+				// pop and push again the same objects to provoke ABA.
+				object := stack.pop()
+				if object == nil {
+					continue
+				}
+				stack.push(object)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if stack.len() != len(objects) {
+		t.Fatalf("stack size mismatch, expect:%d, get:%d", len(objects), stack.len())
+	}
+	seen := make(map[*ObjectHolder]bool)
+	for object := stack.pop(); object != nil; object = stack.pop() {
+		if seen[object] {
+			t.Fatalf("object popped twice, id:%d", object.object.(*test_object).id)
+		}
+		seen[object] = true
+	}
+	if len(seen) != len(objects) {
+		t.Fatalf("objects lost, expect:%d, get:%d", len(objects), len(seen))
+	}
+}
+
+func TestIdleStack_ReleaseReusesSlot(t *testing.T) {
+	var stack idleStack
+	first := NewObjectHolder(&test_object{})
+	stack.register(first)
+	slot := first.slot
+	stack.release(first)
+
+	second := NewObjectHolder(&test_object{})
+	stack.register(second)
+	if second.slot != slot || first.slot != 0 {
+		t.Fatalf("slot should be reused, expect:%d, get:%d", slot, second.slot)
+	}
+}
+
+func TestFastPath_NoAlloc(t *testing.T) {
+	pool := new_test_object_pool(t, 0, 10)
+	defer pool.Close()
+
+	object, _ := pool.GetObject()
+	pool.ReturnObject(object)
+
+	allocs := testing.AllocsPerRun(1000, func() {
+		object, err := pool.GetObject()
+		if err != nil {
+			t.Fatalf("GetObject() failed, err:%s", err)
+		}
+		pool.ReturnObject(object)
+	})
+	if allocs != 0 {
+		t.Fatalf("borrow and return should not allocate, allocs:%v", allocs)
+	}
+	if stats := pool.Stats(); stats.CreatedCount != 1 || stats.IdleObjectCount != 1 || stats.ActiveObjectCount != 0 {
+		t.Fatalf("stats mismatch, stats:%+v", stats)
+	}
+}
+
+func TestFastPath_Evict(t *testing.T) {
+	pool := new_test_object_pool(t, 0, 10)
+	defer pool.Close()
+
+	object, _ := pool.GetObject()
+	pool.ReturnObject(object)
+	if pool.GetIdleObjectCount() != 1 {
+		t.Fatalf("idle object count mismatch, expect:%d, get:%d", 1, pool.GetIdleObjectCount())
+	}
+
+	pool.SetIdleTime(time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	report := pool.EvictIdleObjects()
+	if len(report.EvictedIds) != 1 || pool.GetObjectCount() != 0 {
+		t.Fatalf("parked object should be evicted, report:%+v", report)
+	}
+}
+
+func TestFastPath_Waiter(t *testing.T) {
+	pool := new_test_object_pool(t, 0, 1)
+	defer pool.Close()
+
+	object, _ := pool.GetObject()
+	go func() {
+		time.Sleep(idle_50ms)
+		pool.ReturnObject(object)
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), idle_2s)
+	defer cancel()
+	borrowed, err := pool.GetObjectContext(ctx)
+	if err != nil || borrowed != object {
+		t.Fatalf("waiter should get the returned object, err:%v", err)
+	}
+	pool.ReturnObject(borrowed)
+}
+
+func TestFastPath_ShrinkDestructsOnReturn(t *testing.T) {
+	pool := new_test_object_pool(t, 0, 2)
+	defer pool.Close()
+
+	first, _ := pool.GetObject()
+	second, _ := pool.GetObject()
+	pool.SetMaxObjectCount(1)
+	pool.ReturnObject(first)
+	pool.ReturnObject(second)
+	if pool.GetObjectCount() != 1 {
+		t.Fatalf("surplus object should be destructed, expect:%d, get:%d", 1, pool.GetObjectCount())
+	}
+}
+
+func TestFastPath_ShutdownRace(t *testing.T) {
+	pool := new_test_object_pool(t, 0, 8)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i += 1 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				object, err := pool.GetObject()
+				if err == ErrIsClosed {
+					return
+				}
+				if err == nil {
+					pool.ReturnObject(object)
+				}
+			}
+		}()
+	}
+	time.Sleep(idle_50ms)
+	ctx, cancel := context.WithTimeout(context.Background(), idle_2s)
+	defer cancel()
+	if err := pool.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown() should wait for returns, err:%s", err)
+	}
+	wg.Wait()
+
+	stats := pool.Stats()
+	if stats.ObjectCount != 0 || stats.CreatedCount != stats.DestroyedCount {
+		t.Fatalf("every object should be destructed, stats:%+v", stats)
+	}
+}
+
+func TestFastPath_OffWithoutEvictor(t *testing.T) {
+	pool, err := New(test_object_constructor, WithIdleTimeout(time.Millisecond), WithEvictionInterval(0))
+	if err != nil {
+		t.Fatalf("New() create pool failed, err:%v", err)
+	}
+	defer pool.Close()
+	if pool.fastPath {
+		t.Fatalf("fast path should be off without the evictor")
+	}
+
+	objects := make([]*ObjectHolder, 50)
+	for idx := range objects {
+		objects[idx], _ = pool.GetObject()
+	}
+	for _, object := range objects {
+		pool.ReturnObject(object)
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	// ReturnObject reaps idle objects itself.
+	for i := 0; i < 3; i += 1 {
+		object, _ := pool.GetObject()
+		pool.ReturnObject(object)
+	}
+	if pool.GetObjectCount() >= 50 {
+		t.Fatalf("idle objects should be reaped on return, count:%d", pool.GetObjectCount())
+	}
+}
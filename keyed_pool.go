@@ -0,0 +1,289 @@
+package ObjectPool
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+type KeyedConstructor func(key string) (interface{}, error)
+
+// KeyedPool keeps one sub-pool per key, e.g. per backend address. Options
+// given to NewKeyedPool apply to every key, maxTotal caps the objects of all
+// keys together. When maxTotal is reached, idle objects of other keys are
+// evicted to make room. Keys left without objects are dropped on every
+// eviction pass, with their sub-pool.
+type KeyedPool struct {
+	constructor KeyedConstructor
+	config      Config
+	limiter     *capacityLimiter
+
+	mutex  sync.Mutex
+	pools  map[string]*BasicPool
+	closed bool
+
+	// stops the reaper of empty sub-pools.
+	stopSignal chan struct{}
+	background sync.WaitGroup
+}
+
+func NewKeyedPool(constructor KeyedConstructor, maxTotal uint32, opts ...Option) (*KeyedPool, error) {
+	if constructor == nil {
+		return nil, fmt.Errorf("need parameter constructor")
+	}
+
+	config := DefaultConfig()
+	for _, opt := range opts {
+		opt(&config)
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	if maxTotal != UnlimitedObjectCount && config.MinObjectCount > maxTotal {
+		return nil, fmt.Errorf("min_object should lower or equal to max_total, max_total:%d, min_object:%d", maxTotal, config.MinObjectCount)
+	}
+
+	pool := &KeyedPool{
+		constructor: constructor,
+		config:      config,
+		limiter:     newCapacityLimiter(maxTotal),
+		pools:       make(map[string]*BasicPool),
+		stopSignal:  make(chan struct{}),
+	}
+	if config.EvictionInterval > 0 {
+		pool.background.Add(1)
+		go pool.emptyPoolReaper(config.EvictionInterval)
+	}
+	return pool, nil
+}
+
+func (k *KeyedPool) GetObject(key string) (*ObjectHolder, error) {
+	return k.getObject(context.Background(), key, false)
+}
+
+// GetObjectContext waits until ctx is done when key or the whole pool is
+// exhausted.
+func (k *KeyedPool) GetObjectContext(ctx context.Context, key string) (*ObjectHolder, error) {
+	return k.getObject(ctx, key, true)
+}
+
+func (k *KeyedPool) getObject(ctx context.Context, key string, wait bool) (*ObjectHolder, error) {
+	pool, err := k.subPool(key)
+	if err != nil {
+		return nil, err
+	}
+	return k.getObjectFrom(ctx, key, pool, wait)
+}
+
+// getObjectFrom borrows from pool, the sub-pool of key looked up before.
+func (k *KeyedPool) getObjectFrom(ctx context.Context, key string, pool *BasicPool, wait bool) (*ObjectHolder, error) {
+	for {
+		changed := k.limiter.subscribe()
+
+		object, err := pool.GetObject()
+		if err == ErrIsClosed && !k.IsClosed() {
+			// reaped while empty, the key gets a new sub-pool.
+			k.limiter.unsubscribe()
+			if pool, err = k.subPool(key); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err != ErrReachMaxLimit {
+			k.limiter.unsubscribe()
+			return object, err
+		}
+
+		// evicting other keys only helps when the global limit is the one
+		// reached.
+		if k.limiter.full() && !pool.reachMax() && k.evictIdle(pool) {
+			k.limiter.unsubscribe()
+			continue
+		}
+
+		if !wait {
+			k.limiter.unsubscribe()
+			return nil, ErrReachMaxLimit
+		}
+		select {
+		case <-changed:
+			k.limiter.unsubscribe()
+		case <-ctx.Done():
+			k.limiter.unsubscribe()
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func (k *KeyedPool) ReturnObject(key string, object *ObjectHolder) error {
+	k.mutex.Lock()
+	pool, has := k.pools[key]
+	k.mutex.Unlock()
+
+	if !has {
+		return ErrNotExists
+	}
+	return pool.ReturnObject(object)
+}
+
+func (k *KeyedPool) subPool(key string) (*BasicPool, error) {
+	k.mutex.Lock()
+	if k.closed {
+		k.mutex.Unlock()
+		return nil, ErrIsClosed
+	}
+	if pool, has := k.pools[key]; has {
+		k.mutex.Unlock()
+		return pool, nil
+	}
+
+	config := k.config
+	// prefilled below, constructors must not run under the lock of all keys.
+	config.Prefill = false
+	pool, err := newObjectPool(contextConstructor(func() (interface{}, error) {
+		return k.constructor(key)
+	}, config), config, k.limiter)
+	if err != nil {
+		k.mutex.Unlock()
+		return nil, fmt.Errorf("create pool failed, key:%s, err:%s", key, err)
+	}
+	k.pools[key] = pool
+	k.mutex.Unlock()
+
+	if !k.config.Prefill {
+		return pool, nil
+	}
+	failures, err := pool.fillMinObjects()
+	if failures > k.config.PrefillMaxFailures {
+		k.mutex.Lock()
+		if k.pools[key] == pool {
+			delete(k.pools, key)
+		}
+		k.mutex.Unlock()
+		pool.Close()
+		return nil, fmt.Errorf("prefill pool failed, key:%s, failures:%d, constructor_error:%s", key, failures, err)
+	}
+	return pool, nil
+}
+
+// evictIdle destroys the least recently used idle object of any key but
+// the exhausted one.
+func (k *KeyedPool) evictIdle(except *BasicPool) bool {
+	k.mutex.Lock()
+	pools := make([]*BasicPool, 0, len(k.pools))
+	for _, pool := range k.pools {
+		if pool != except {
+			pools = append(pools, pool)
+		}
+	}
+	k.mutex.Unlock()
+
+	var oldest *BasicPool
+	var oldestTime time.Time
+	for _, pool := range pools {
+		lastUseTime, has := pool.oldestIdleTime()
+		if has && (oldest == nil || lastUseTime.Before(oldestTime)) {
+			oldest = pool
+			oldestTime = lastUseTime
+		}
+	}
+	if oldest == nil {
+		return false
+	}
+	return oldest.evictOldestIdle()
+}
+
+// reapEmptyPools closes and drops the sub-pools of keys without objects,
+// so goroutines and maps do not grow as keys churn.
+func (k *KeyedPool) reapEmptyPools() int {
+	k.mutex.Lock()
+	var reaped []*BasicPool
+	for key, pool := range k.pools {
+		if pool.closeIfEmpty() {
+			delete(k.pools, key)
+			reaped = append(reaped, pool)
+		}
+	}
+	k.mutex.Unlock()
+
+	for _, pool := range reaped {
+		pool.Close()
+	}
+	return len(reaped)
+}
+
+func (k *KeyedPool) emptyPoolReaper(interval time.Duration) {
+	defer k.background.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			k.reapEmptyPools()
+		case <-k.stopSignal:
+			return
+		}
+	}
+}
+
+func (k *KeyedPool) Close() {
+	k.mutex.Lock()
+	if k.closed {
+		k.mutex.Unlock()
+		return
+	}
+	k.closed = true
+	pools := k.pools
+	close(k.stopSignal)
+	k.mutex.Unlock()
+
+	k.background.Wait()
+	for _, pool := range pools {
+		pool.Close()
+	}
+	// wake waiters, they see ErrIsClosed.
+	k.limiter.broadcast()
+}
+
+func (k *KeyedPool) IsClosed() bool {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+	return k.closed
+}
+
+// GetObjectCount returns the number of objects of all keys.
+func (k *KeyedPool) GetObjectCount() uint32 {
+	return uint32(k.limiter.count.Load())
+}
+
+func (k *KeyedPool) GetMaxObjectCount() uint32 {
+	return k.limiter.maxObjectCount
+}
+
+func (k *KeyedPool) Keys() []string {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	keys := make([]string, 0, len(k.pools))
+	for key := range k.pools {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Stats returns a snapshot per key.
+func (k *KeyedPool) Stats() map[string]Stats {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	stats := make(map[string]Stats, len(k.pools))
+	for key, pool := range k.pools {
+		stats[key] = pool.Stats()
+	}
+	return stats
+}
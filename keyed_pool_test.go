@@ -0,0 +1,215 @@
+package ObjectPool
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func keyed_test_constructor(key string) (interface{}, error) {
+	return &test_object{}, nil
+}
+
+func new_test_keyed_pool(t *testing.T, max_total uint32, opts ...Option) *KeyedPool {
+	pool, err := NewKeyedPool(keyed_test_constructor, max_total, opts...)
+	if err != nil {
+		t.Fatalf("NewKeyedPool() create pool failed, err:%v", err)
+	}
+	return pool
+}
+
+func TestKeyedPool_PerKeyMax(t *testing.T) {
+	pool := new_test_keyed_pool(t, 10, WithMaxObjects(2))
+	defer pool.Close()
+
+	for i := 0; i < 2; i += 1 {
+		if _, err := pool.GetObject("a"); err != nil {
+			t.Fatalf("GetObject() failed, err:%s", err)
+		}
+	}
+	if _, err := pool.GetObject("a"); err != ErrReachMaxLimit {
+		t.Fatalf("per key max should be reached, err:%v", err)
+	}
+	if _, err := pool.GetObject("b"); err != nil {
+		t.Fatalf("other key should not be limited, err:%v", err)
+	}
+	if pool.GetObjectCount() != 3 {
+		t.Fatalf("object count mismatch, expect:%d, get:%d", 3, pool.GetObjectCount())
+	}
+}
+
+func TestKeyedPool_GlobalMaxEvictsOtherKey(t *testing.T) {
+	pool := new_test_keyed_pool(t, 2)
+	defer pool.Close()
+
+	a1, _ := pool.GetObject("a")
+	a2, _ := pool.GetObject("a")
+	if err := pool.ReturnObject("a", a1); err != nil {
+		t.Fatalf("ReturnObject() failed, err:%s", err)
+	}
+
+	// key "a" holds an idle object, it is evicted for key "b".
+	if _, err := pool.GetObject("b"); err != nil {
+		t.Fatalf("GetObject() should evict idle object of other key, err:%v", err)
+	}
+	stats := pool.Stats()
+	if stats["a"].IdleObjectCount != 0 || stats["a"].EvictedCount != 1 {
+		t.Fatalf("idle object of key a should be evicted, stats:%+v", stats["a"])
+	}
+
+	// nothing idle is left anywhere.
+	if _, err := pool.GetObject("c"); err != ErrReachMaxLimit {
+		t.Fatalf("global max should be reached, err:%v", err)
+	}
+	if pool.GetObjectCount() != 2 {
+		t.Fatalf("object count mismatch, expect:%d, get:%d", 2, pool.GetObjectCount())
+	}
+	pool.ReturnObject("a", a2)
+}
+
+func TestKeyedPool_WaitOtherKey(t *testing.T) {
+	pool := new_test_keyed_pool(t, 1)
+	defer pool.Close()
+
+	object, _ := pool.GetObject("a")
+	go func() {
+		time.Sleep(idle_50ms)
+		pool.ReturnObject("a", object)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), idle_2s)
+	defer cancel()
+	if _, err := pool.GetObjectContext(ctx, "b"); err != nil {
+		t.Fatalf("GetObjectContext() should be woken by return of other key, err:%v", err)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), idle_50ms)
+	defer cancel()
+	if _, err := pool.GetObjectContext(ctx, "c"); err != context.DeadlineExceeded {
+		t.Fatalf("GetObjectContext() should time out, err:%v", err)
+	}
+}
+
+func TestKeyedPool_Close(t *testing.T) {
+	pool := new_test_keyed_pool(t, 0)
+
+	for i := 0; i < 3; i += 1 {
+		object, _ := pool.GetObject(fmt.Sprintf("key_%d", i))
+		pool.ReturnObject(fmt.Sprintf("key_%d", i), object)
+	}
+	if len(pool.Keys()) != 3 {
+		t.Fatalf("key count mismatch, expect:%d, get:%d", 3, len(pool.Keys()))
+	}
+
+	pool.Close()
+	if _, err := pool.GetObject("key_0"); err != ErrIsClosed {
+		t.Fatalf("closed pool should not lend, err:%v", err)
+	}
+	if _, err := pool.GetObject("other"); err != ErrIsClosed {
+		t.Fatalf("closed pool should not create key, err:%v", err)
+	}
+	if pool.GetObjectCount() != 0 {
+		t.Fatalf("closed pool should be empty, get:%d", pool.GetObjectCount())
+	}
+}
+
+func TestKeyedPool_ReapEmptyPools(t *testing.T) {
+	before := runtime.NumGoroutine()
+	// objects are used once, so every key is empty after its return.
+	pool := new_test_keyed_pool(t, 100, WithMaxUseCount(1), WithEvictionInterval(time.Hour))
+	defer pool.Close()
+
+	for i := 0; i < 20; i += 1 {
+		key := fmt.Sprintf("backend_%d", i)
+		object, err := pool.GetObject(key)
+		if err != nil {
+			t.Fatalf("GetObject() failed, key:%s, err:%s", key, err)
+		}
+		pool.ReturnObject(key, object)
+	}
+	kept, _ := pool.GetObject("kept")
+
+	if reaped := pool.reapEmptyPools(); reaped != 20 {
+		t.Fatalf("empty sub-pools should be reaped, expect:%d, get:%d", 20, reaped)
+	}
+	if keys := pool.Keys(); len(keys) != 1 || keys[0] != "kept" {
+		t.Fatalf("only the key with objects should be kept, keys:%v", keys)
+	}
+	// the reaper itself and the kept sub-pool's goroutines remain.
+	if after := runtime.NumGoroutine(); after > before+4 {
+		t.Fatalf("goroutines of reaped sub-pools should exit, before:%d, after:%d", before, after)
+	}
+
+	if _, err := pool.GetObject("backend_0"); err != nil {
+		t.Fatalf("reaped key should get a new sub-pool, err:%s", err)
+	}
+	pool.ReturnObject("kept", kept)
+}
+
+func TestKeyedPool_BorrowAfterReap(t *testing.T) {
+	pool := new_test_keyed_pool(t, 100, WithMaxUseCount(1))
+	defer pool.Close()
+
+	object, _ := pool.GetObject("a")
+	pool.ReturnObject("a", object)
+	sub, _ := pool.subPool("a")
+	pool.reapEmptyPools()
+	if !sub.IsClosed() {
+		t.Fatalf("empty sub-pool should be closed")
+	}
+
+	// a borrower holding the reaped sub-pool moves to a new one.
+	if _, err := pool.getObjectFrom(context.Background(), "a", sub, false); err != nil {
+		t.Fatalf("GetObject() should retry on a new sub-pool, err:%s", err)
+	}
+}
+
+func TestKeyedPool_SlowPrefillDoesNotBlockOtherKeys(t *testing.T) {
+	pool, err := NewKeyedPool(func(key string) (interface{}, error) {
+		if key == "slow" {
+			time.Sleep(500 * time.Millisecond)
+		}
+		return &test_object{}, nil
+	}, 100, WithMinObjects(1), WithPrefill(1, 0))
+	if err != nil {
+		t.Fatalf("NewKeyedPool() create pool failed, err:%v", err)
+	}
+	defer pool.Close()
+
+	object, err := pool.GetObject("fast")
+	if err != nil {
+		t.Fatalf("GetObject() failed, err:%s", err)
+	}
+	go pool.GetObject("slow")
+	time.Sleep(idle_50ms)
+
+	start := time.Now()
+	if err := pool.ReturnObject("fast", object); err != nil {
+		t.Fatalf("ReturnObject() failed, err:%s", err)
+	}
+	if _, err := pool.GetObject("fast"); err != nil {
+		t.Fatalf("GetObject() failed, err:%s", err)
+	}
+	if elapsed := time.Since(start); elapsed > 200*time.Millisecond {
+		t.Fatalf("slow key warm-up should not block other keys, elapsed:%s", elapsed)
+	}
+}
+
+func TestKeyedPool_PrefillFailure(t *testing.T) {
+	pool, err := NewKeyedPool(func(key string) (interface{}, error) {
+		return nil, fmt.Errorf("dial failed, key:%s", key)
+	}, 100, WithMinObjects(1), WithPrefill(1, 0))
+	if err != nil {
+		t.Fatalf("NewKeyedPool() create pool failed, err:%v", err)
+	}
+	defer pool.Close()
+
+	if _, err := pool.GetObject("down"); err == nil {
+		t.Fatalf("GetObject() should fail when prefill fails")
+	}
+	if keys := pool.Keys(); len(keys) != 0 {
+		t.Fatalf("failed sub-pool should be dropped, keys:%v", keys)
+	}
+}
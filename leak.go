@@ -0,0 +1,159 @@
+package ObjectPool
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	maxBorrowerStackDepth = 32
+	minLeakCheckInterval  = 10 * time.Millisecond
+)
+
+// LeakReport describes an object borrowed longer than the leak detection
+// threshold.
+type LeakReport struct {
+	Id         string
+	BorrowTime time.Time
+	HeldFor    time.Duration
+	UseCount   uint64
+	// where GetObject() was called.
+	Stack string
+}
+
+func (r LeakReport) String() string {
+	return fmt.Sprintf("object borrowed for %s was not returned, id:%s, borrow_time:%s, use_count:%d, borrowed at:\n%s",
+		r.HeldFor, r.Id, r.BorrowTime.Format(time.RFC3339Nano), r.UseCount, r.Stack)
+}
+
+func borrowerStack() []uintptr {
+	stack := make([]uintptr, maxBorrowerStackDepth)
+	// skip runtime.Callers, borrowerStack and getObject.
+	return stack[:runtime.Callers(3, stack)]
+}
+
+func formatStack(stack []uintptr) string {
+	if len(stack) == 0 {
+		return ""
+	}
+	var builder strings.Builder
+	frames := runtime.CallersFrames(stack)
+	for {
+		frame, more := frames.Next()
+		fmt.Fprintf(&builder, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		if !more {
+			break
+		}
+	}
+	return builder.String()
+}
+
+// LeakSuspects lists objects currently borrowed longer than the leak
+// detection threshold, longest held first.
+func (p *BasicPool) LeakSuspects() []LeakReport {
+	return p.collectLeaks(false)
+}
+
+// DumpLeaks writes LeakSuspects() to w for debugging.
+func (p *BasicPool) DumpLeaks(w io.Writer) error {
+	reports := p.LeakSuspects()
+	if _, err := fmt.Fprintf(w, "%d leak suspects, threshold:%s\n", len(reports), p.config.LeakDetectionThreshold); err != nil {
+		return err
+	}
+	for idx, report := range reports {
+		if _, err := fmt.Fprintf(w, "#%d %s\n", idx, report); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// collectLeaks returns leak suspects, with onlyNew set only those not
+// reported before, marking them reported.
+func (p *BasicPool) collectLeaks(onlyNew bool) []LeakReport {
+	threshold := p.config.LeakDetectionThreshold
+	if threshold <= 0 {
+		return nil
+	}
+
+	now := time.Now()
+	type suspect struct {
+		report LeakReport
+		stack  []uintptr
+	}
+	var suspects []suspect
+
+	p.mutex.Lock()
+	for object := range p.activePool {
+		// being constructed or validated, not held by a borrower.
+		borrowTime := object.borrowedAt()
+		if borrowTime.IsZero() || now.Sub(borrowTime) < threshold {
+			continue
+		}
+		if onlyNew {
+			if object.leakReported {
+				continue
+			}
+			object.leakReported = true
+		}
+		suspects = append(suspects, suspect{
+			report: LeakReport{
+				Id:         object.id,
+				BorrowTime: borrowTime,
+				HeldFor:    now.Sub(borrowTime),
+				UseCount:   object.useCount.Load(),
+			},
+			stack: object.borrowStack,
+		})
+	}
+	p.mutex.Unlock()
+
+	reports := make([]LeakReport, len(suspects))
+	for idx, suspect := range suspects {
+		reports[idx] = suspect.report
+		reports[idx].Stack = formatStack(suspect.stack)
+	}
+	sort.Slice(reports, func(i, j int) bool {
+		return reports[i].BorrowTime.Before(reports[j].BorrowTime)
+	})
+	return reports
+}
+
+func (p *BasicPool) leakDetector() {
+	defer p.background.Done()
+
+	interval := p.config.LeakDetectionThreshold / 2
+	if interval < minLeakCheckInterval {
+		interval = minLeakCheckInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			for _, report := range p.collectLeaks(true) {
+				if p.config.LeakReporter != nil {
+					p.config.LeakReporter(report)
+				} else if p.config.Logger != nil {
+					p.logger.Warn("object leak suspected",
+						slog.String("object_id", report.Id),
+						slog.Duration("held_for", report.HeldFor),
+						slog.Time("borrow_time", report.BorrowTime),
+						slog.Uint64("use_count", report.UseCount),
+						slog.String("stack", report.Stack))
+				} else {
+					log.Printf("object pool: %s", report)
+				}
+			}
+		case <-p.stopSignal:
+			return
+		}
+	}
+}
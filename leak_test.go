@@ -0,0 +1,76 @@
+package ObjectPool
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func leaking_borrower(t *testing.T, pool *BasicPool) *ObjectHolder {
+	return get_object_and_check(t, pool)
+}
+
+func TestLeakDetection_Report(t *testing.T) {
+	reports := make(chan LeakReport, 10)
+	pool, err := New(test_object_constructor,
+			WithLeakDetection(idle_50ms, func(report LeakReport) {
+				reports <- report
+			}))
+	if err != nil {
+		t.Fatalf("New() create pool failed, err:%v", err)
+	}
+	defer pool.Close()
+
+	returned := get_object_and_check(t, pool)
+	leaking_borrower(t, pool)
+	pool.ReturnObject(returned)
+
+	var report LeakReport
+	select {
+	case report = <-reports:
+	case <-time.After(idle_2s):
+		t.Fatalf("leaked object should be reported")
+	}
+	if report.HeldFor < idle_50ms || report.UseCount != 1 || report.Id == "" {
+		t.Fatalf("invalid leak report:%s", report)
+	}
+	if !strings.Contains(report.Stack, "leaking_borrower") {
+		t.Fatalf("leak report should contain the borrower stack, stack:%s", report.Stack)
+	}
+
+	// reported only once.
+	select {
+	case report = <-reports:
+		t.Fatalf("leak should be reported once, report:%s", report)
+	case <-time.After(3 * idle_50ms):
+	}
+}
+
+func TestLeakDetection_Dump(t *testing.T) {
+	pool, err := New(test_object_constructor,
+			WithLeakDetection(idle_50ms, func(LeakReport) {}))
+	if err != nil {
+		t.Fatalf("New() create pool failed, err:%v", err)
+	}
+	defer pool.Close()
+
+	leaked := leaking_borrower(t, pool)
+	if len(pool.LeakSuspects()) != 0 {
+		t.Fatalf("object borrowed shortly should not be a suspect")
+	}
+
+	time.Sleep(2 * idle_50ms)
+	var buffer bytes.Buffer
+	if err := pool.DumpLeaks(&buffer); err != nil {
+		t.Fatalf("DumpLeaks() failed, err:%s", err)
+	}
+	if !strings.HasPrefix(buffer.String(), "1 leak suspects") || !strings.Contains(buffer.String(), "leaking_borrower") {
+		t.Fatalf("invalid dump:%s", buffer.String())
+	}
+
+	pool.ReturnObject(leaked)
+	if len(pool.LeakSuspects()) != 0 {
+		t.Fatalf("returned object should not be a suspect")
+	}
+}
@@ -0,0 +1,85 @@
+package ObjectPool
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// capacityLimiter caps the objects of several objectPools together, the
+// sub-pools of a KeyedPool share one.
+type capacityLimiter struct {
+	maxObjectCount uint32
+	count          atomic.Int64
+
+	// callers between subscribe() and unsubscribe(), broadcast() is skipped
+	// without them.
+	waiters atomic.Int32
+	mutex   sync.Mutex
+	// closed and replaced whenever capacity is freed or an object becomes
+	// idle.
+	changed chan struct{}
+}
+
+func newCapacityLimiter(max_object uint32) *capacityLimiter {
+	return &capacityLimiter{
+		maxObjectCount: max_object,
+		changed:        make(chan struct{}),
+	}
+}
+
+func (l *capacityLimiter) acquire() bool {
+	for {
+		count := l.count.Load()
+		if l.maxObjectCount != UnlimitedObjectCount && count >= int64(l.maxObjectCount) {
+			return false
+		}
+		if l.count.CompareAndSwap(count, count+1) {
+			return true
+		}
+	}
+}
+
+func (l *capacityLimiter) release(count int) {
+	l.count.Add(-int64(count))
+	l.broadcast()
+}
+
+func (l *capacityLimiter) full() bool {
+	return l.maxObjectCount != UnlimitedObjectCount && l.count.Load() >= int64(l.maxObjectCount)
+}
+
+func (l *capacityLimiter) broadcast() {
+	if l.waiters.Load() == 0 {
+		return
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	close(l.changed)
+	l.changed = make(chan struct{})
+}
+
+// subscribe must be called before checking for capacity, so a change right
+// after the check is not missed. Every call needs an unsubscribe().
+func (l *capacityLimiter) subscribe() <-chan struct{} {
+	l.waiters.Add(1)
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.changed
+}
+
+func (l *capacityLimiter) unsubscribe() {
+	l.waiters.Add(-1)
+}
+
+// syncLimiterLocked gives slots of objects that left the pool back to the
+// limiter.
+func (p *BasicPool) syncLimiterLocked() {
+	if p.limiter == nil {
+		return
+	}
+	held := len(p.idlePool) + len(p.activePool)
+	if held < p.limiterHeld {
+		p.limiter.release(p.limiterHeld - held)
+		p.limiterHeld = held
+	}
+}
@@ -0,0 +1,52 @@
+package ObjectPool
+
+import "time"
+
+// Listener is notified of object lifecycle events. Callbacks run on the
+// goroutine causing the event without the pool mutex held, they should be
+// fast and must not block.
+type Listener interface {
+	OnCreate(info HolderInfo)
+	OnCreateFailed(err error)
+	OnBorrow(info HolderInfo)
+	OnReturn(info HolderInfo)
+	// the borrower marked the object unusable, it is destroyed next.
+	OnMarkUnusable(info HolderInfo)
+	// the pool dropped an idle object, because of idle timeout, expiry,
+	// failed validation or shrinking. It is destroyed next.
+	OnEvict(info HolderInfo)
+	OnDestroy(info HolderInfo)
+}
+
+// NopListener ignores all events, embed it to implement only some
+// callbacks.
+type NopListener struct{}
+
+func (NopListener) OnCreate(HolderInfo)       {}
+func (NopListener) OnCreateFailed(error)      {}
+func (NopListener) OnBorrow(HolderInfo)       {}
+func (NopListener) OnReturn(HolderInfo)       {}
+func (NopListener) OnMarkUnusable(HolderInfo) {}
+func (NopListener) OnEvict(HolderInfo)        {}
+func (NopListener) OnDestroy(HolderInfo)      {}
+
+// destroyObject destructs an object that left the pool.
+func (p *BasicPool) destroyObject(object *ObjectHolder) {
+	p.mutex.Lock()
+	if p.objects[object.id] == object {
+		delete(p.objects, object.id)
+	}
+	p.mutex.Unlock()
+	p.idleStack.release(object)
+	start := time.Now()
+	p.destructor(object.object)
+	p.logSlow("destruct", object.id, time.Since(start))
+	p.listener.OnDestroy(object.info())
+}
+
+// evictObject destroys an idle object that left the pool.
+func (p *BasicPool) evictObject(object *ObjectHolder, reason string) {
+	p.logEvict(object.id, reason)
+	p.listener.OnEvict(object.info())
+	p.destroyObject(object)
+}
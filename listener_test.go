@@ -0,0 +1,98 @@
+package ObjectPool
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+type recording_listener struct {
+	NopListener
+	mutex  sync.Mutex
+	events []string
+}
+
+func (l *recording_listener) record(event string, info HolderInfo) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.events = append(l.events, fmt.Sprintf("%s:%s", event, info.Id))
+}
+
+func (l *recording_listener) OnCreate(info HolderInfo)       { l.record("create", info) }
+func (l *recording_listener) OnBorrow(info HolderInfo)       { l.record("borrow", info) }
+func (l *recording_listener) OnReturn(info HolderInfo)       { l.record("return", info) }
+func (l *recording_listener) OnMarkUnusable(info HolderInfo) { l.record("unusable", info) }
+func (l *recording_listener) OnEvict(info HolderInfo)        { l.record("evict", info) }
+func (l *recording_listener) OnDestroy(info HolderInfo)      { l.record("destroy", info) }
+
+func (l *recording_listener) OnCreateFailed(err error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.events = append(l.events, "create_failed")
+}
+
+func (l *recording_listener) take() []string {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	events := l.events
+	l.events = nil
+	return events
+}
+
+func TestListener_Lifecycle(t *testing.T) {
+	listener := &recording_listener{}
+	pool, err := New(test_object_constructor,
+			WithListener(listener),
+			WithIdExtractor(func(object interface{}) string { return "obj" }))
+	if err != nil {
+		t.Fatalf("New() create pool failed, err:%v", err)
+	}
+
+	object := get_object_and_check(t, pool)
+	pool.ReturnObject(object)
+	object = get_object_and_check(t, pool)
+	object.MarkUnusable()
+	pool.ReturnObject(object)
+
+	expect := []string{"create:obj", "borrow:obj", "return:obj", "borrow:obj",
+		"return:obj", "unusable:obj", "destroy:obj"}
+	if events := listener.take(); !reflect.DeepEqual(events, expect) {
+		t.Fatalf("events mismatch, expect:%v, get:%v", expect, events)
+	}
+
+	pool.ReturnObject(get_object_and_check(t, pool))
+	pool.EvictIdleObjects()
+	pool.SetIdleTime(0)
+	pool.EvictIdleObjects()
+	expect = []string{"create:obj", "borrow:obj", "return:obj", "evict:obj", "destroy:obj"}
+	if events := listener.take(); !reflect.DeepEqual(events, expect) {
+		t.Fatalf("events mismatch, expect:%v, get:%v", expect, events)
+	}
+
+	pool.ReturnObject(get_object_and_check(t, pool))
+	listener.take()
+	pool.Close()
+	if events := listener.take(); !reflect.DeepEqual(events, []string{"destroy:obj"}) {
+		t.Fatalf("Close() should destroy idle object, events:%v", events)
+	}
+}
+
+func TestListener_CreateFailed(t *testing.T) {
+	listener := &recording_listener{}
+	pool, err := New(func() (interface{}, error) {
+				return nil, errors.New("create failed")
+			}, WithListener(listener))
+	if err != nil {
+		t.Fatalf("New() create pool failed, err:%v", err)
+	}
+	defer pool.Close()
+
+	if _, err := pool.GetObject(); err == nil {
+		t.Fatalf("GetObject() should fail")
+	}
+	if events := listener.take(); !reflect.DeepEqual(events, []string{"create_failed"}) {
+		t.Fatalf("events mismatch, get:%v", events)
+	}
+}
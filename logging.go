@@ -0,0 +1,53 @@
+package ObjectPool
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// eviction reasons in log records.
+const (
+	evictIdleTimeout = "idle_timeout"
+	evictExpired     = "max_lifetime"
+	evictInvalid     = "idle_validator"
+	evictResize      = "resize"
+	evictKeyedPool   = "keyed_pool"
+)
+
+// discardHandler keeps the pool silent without Config.Logger.
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (h discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return h }
+func (h discardHandler) WithGroup(string) slog.Handler           { return h }
+
+func newPoolLogger(config Config) *slog.Logger {
+	if config.Logger == nil {
+		return slog.New(discardHandler{})
+	}
+	if config.Name == "" {
+		return config.Logger
+	}
+	return config.Logger.With(slog.String("pool", config.Name))
+}
+
+func (p *BasicPool) logEvict(id string, reason string) {
+	p.logger.Info("object evicted",
+		slog.String("object_id", id),
+		slog.String("reason", reason))
+}
+
+// logSlow logs an operation on object id that took longer than
+// SlowThreshold.
+func (p *BasicPool) logSlow(operation string, id string, duration time.Duration) {
+	if p.config.SlowThreshold <= 0 || duration < p.config.SlowThreshold {
+		return
+	}
+	p.logger.Warn("slow object pool operation",
+		slog.String("operation", operation),
+		slog.String("object_id", id),
+		slog.Duration("duration", duration),
+		slog.Duration("threshold", p.config.SlowThreshold))
+}
@@ -0,0 +1,115 @@
+package ObjectPool
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// locked_buffer lets the pool log from background goroutines.
+type locked_buffer struct {
+	mutex  sync.Mutex
+	buffer bytes.Buffer
+}
+
+func (b *locked_buffer) Write(data []byte) (int, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.buffer.Write(data)
+}
+
+func (b *locked_buffer) String() string {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.buffer.String()
+}
+
+func new_test_logger() (*slog.Logger, *locked_buffer) {
+	buffer := &locked_buffer{}
+	return slog.New(slog.NewTextHandler(buffer, &slog.HandlerOptions{Level: slog.LevelDebug})), buffer
+}
+
+func TestLogging_CreateFailed(t *testing.T) {
+	logger, buffer := new_test_logger()
+	pool, err := New(func() (interface{}, error) {
+				return nil, errors.New("connection refused")
+			}, WithLogger(logger, "backend"))
+	if err != nil {
+		t.Fatalf("New() create pool failed, err:%v", err)
+	}
+	defer pool.Close()
+
+	pool.GetObject()
+	output := buffer.String()
+	if !strings.Contains(output, `msg="create object failed" pool=backend error="connection refused"`) {
+		t.Fatalf("create failure should be logged, log:%s", output)
+	}
+}
+
+func TestLogging_EvictAndForceClose(t *testing.T) {
+	logger, buffer := new_test_logger()
+	pool, err := New(test_object_constructor,
+			WithLogger(logger, "backend"),
+			WithIdExtractor(test_object_id_extractor),
+			WithIdleTimeout(0))
+	if err != nil {
+		t.Fatalf("New() create pool failed, err:%v", err)
+	}
+
+	idle := return_in_order(t, pool, 1)[0]
+	pool.EvictIdleObjects()
+	if !strings.Contains(buffer.String(), "object_id="+idle.GetId()+" reason=idle_timeout") {
+		t.Fatalf("eviction should be logged, log:%s", buffer.String())
+	}
+
+	borrowed := get_object_and_check(t, pool)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	pool.Shutdown(ctx)
+	if !strings.Contains(buffer.String(), `msg="borrowed objects force closed" pool=backend object_ids=[`+borrowed.GetId()+"]") {
+		t.Fatalf("force close should be logged, log:%s", buffer.String())
+	}
+}
+
+func TestLogging_Slow(t *testing.T) {
+	logger, buffer := new_test_logger()
+	pool, err := New(func() (interface{}, error) {
+				time.Sleep(idle_50ms)
+				return test_object_constructor()
+			},
+			WithLogger(logger, ""),
+			WithSlowThreshold(idle_50ms / 2))
+	if err != nil {
+		t.Fatalf("New() create pool failed, err:%v", err)
+	}
+	defer pool.Close()
+
+	get_object_and_check(t, pool)
+	output := buffer.String()
+	if !strings.Contains(output, "operation=construct") || !strings.Contains(output, "operation=borrow") {
+		t.Fatalf("slow construction and borrow should be logged, log:%s", output)
+	}
+	if strings.Contains(output, "pool=") {
+		t.Fatalf("unnamed pool should not be logged with name, log:%s", output)
+	}
+}
+
+func TestLogging_Silent(t *testing.T) {
+	pool, err := New(func() (interface{}, error) {
+				return nil, errors.New("connection refused")
+			})
+	if err != nil {
+		t.Fatalf("New() create pool failed, err:%v", err)
+	}
+	defer pool.Close()
+
+	if pool.logger.Enabled(context.Background(), slog.LevelError) {
+		t.Fatalf("pool without logger should be silent")
+	}
+	pool.GetObject()
+}
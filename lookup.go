@@ -0,0 +1,123 @@
+package ObjectPool
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// HolderInfo is a snapshot of a pooled object's metadata.
+type HolderInfo struct {
+	Id          string
+	CreateTime  time.Time
+	LastUseTime time.Time
+	UseCount    uint64
+	// zero if the object is idle.
+	BorrowTime time.Time
+	Borrowed   bool
+	Usable     bool
+	// zero if the object never expires.
+	ExpireTime time.Time
+}
+
+func (o *ObjectHolder) info() HolderInfo {
+	return HolderInfo{
+		Id:          o.id,
+		CreateTime:  o.createTime,
+		LastUseTime: o.lastUse(),
+		UseCount:    o.useCount.Load(),
+		BorrowTime:  o.borrowedAt(),
+		Borrowed:    o.state.Load() == holderBorrowed,
+		Usable:      o.usable.Load() && !o.invalidated.Load(),
+		ExpireTime:  o.expireTime,
+	}
+}
+
+// findLocked returns the idle or borrowed object with id, objects being
+// constructed have no id yet.
+func (p *BasicPool) findLocked(id string) *ObjectHolder {
+	return p.objects[id]
+}
+
+// Lookup returns the metadata of the pooled object with id.
+func (p *BasicPool) Lookup(id string) (HolderInfo, bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	object := p.findLocked(id)
+	if object == nil {
+		return HolderInfo{}, false
+	}
+	return object.info(), true
+}
+
+// InvalidateByID destructs the idle object with id at once, a borrowed one
+// is destructed when it is returned. It reports whether the object was
+// found.
+func (p *BasicPool) InvalidateByID(id string) bool {
+	p.mutex.Lock()
+	if p.closed {
+		p.mutex.Unlock()
+		return false
+	}
+
+	// a parked object can be removed like an idle one.
+	p.flushParkedLocked()
+	object := p.findLocked(id)
+	if object == nil {
+		p.mutex.Unlock()
+		return false
+	}
+	object.invalidated.Store(true)
+	if !p.removeIdleLocked(object) {
+		p.mutex.Unlock()
+		return true
+	}
+	p.destroyedCount += 1
+	p.releaseSlotLocked()
+	p.mutex.Unlock()
+
+	p.destroyObject(object)
+	return true
+}
+
+// Objects lists every pooled object ordered by id.
+func (p *BasicPool) Objects() []HolderInfo {
+	p.mutex.Lock()
+	infos := make([]HolderInfo, 0, len(p.idlePool)+len(p.activePool))
+	for _, object := range p.idlePool {
+		infos = append(infos, object.info())
+	}
+	for object := range p.activePool {
+		if object.id != "" {
+			infos = append(infos, object.info())
+		}
+	}
+	p.mutex.Unlock()
+
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Id < infos[j].Id
+	})
+	return infos
+}
+
+// DumpObjects writes Objects() to w for debugging.
+func (p *BasicPool) DumpObjects(w io.Writer) error {
+	infos := p.Objects()
+	if _, err := fmt.Fprintf(w, "%d objects\n", len(infos)); err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, info := range infos {
+		state := "idle"
+		if info.Borrowed {
+			state = fmt.Sprintf("borrowed for %s", now.Sub(info.BorrowTime))
+		}
+		if _, err := fmt.Fprintf(w, "id:%s, state:%s, use_count:%d, usable:%t, age:%s\n",
+			info.Id, state, info.UseCount, info.Usable, now.Sub(info.CreateTime)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package ObjectPool
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func new_lookup_test_pool(t *testing.T, fixture *validator_fixture) *BasicPool {
+	pool, err := New(test_object_constructor,
+			WithDestructor(fixture.destruct),
+			WithIdExtractor(test_object_id_extractor))
+	if err != nil {
+		t.Fatalf("New() create pool failed, err:%v", err)
+	}
+	return pool
+}
+
+func TestLookup(t *testing.T) {
+	pool := new_lookup_test_pool(t, new_validator_fixture())
+	defer pool.Close()
+
+	objects := return_in_order(t, pool, 2)
+	borrowed := get_object_and_check(t, pool)
+
+	info, has := pool.Lookup(borrowed.GetId())
+	if !has || !info.Borrowed || info.BorrowTime.IsZero() || info.UseCount != 2 {
+		t.Fatalf("borrowed object info mismatch, has:%t, info:%+v", has, info)
+	}
+	idle := objects[0]
+	info, has = pool.Lookup(idle.GetId())
+	if !has || info.Borrowed || info.Id != idle.GetId() {
+		t.Fatalf("idle object info mismatch, has:%t, info:%+v", has, info)
+	}
+	if _, has := pool.Lookup("unknown"); has {
+		t.Fatalf("unknown id should not be found")
+	}
+
+	if len(pool.Objects()) != 2 {
+		t.Fatalf("object count mismatch, expect:%d, get:%d", 2, len(pool.Objects()))
+	}
+	var buffer bytes.Buffer
+	if err := pool.DumpObjects(&buffer); err != nil {
+		t.Fatalf("DumpObjects() failed, err:%s", err)
+	}
+	if !strings.Contains(buffer.String(), "id:"+borrowed.GetId()+", state:borrowed") {
+		t.Fatalf("dump should contain borrowed object, dump:%s", buffer.String())
+	}
+	pool.ReturnObject(borrowed)
+}
+
+func TestInvalidateByID(t *testing.T) {
+	fixture := new_validator_fixture()
+	pool := new_lookup_test_pool(t, fixture)
+	defer pool.Close()
+
+	objects := return_in_order(t, pool, 2)
+	borrowed := get_object_and_check(t, pool)
+	idle := objects[0]
+
+	if !pool.InvalidateByID(idle.GetId()) {
+		t.Fatalf("idle object should be invalidated")
+	}
+	if fixture.destructed_count() != 1 || pool.GetIdleObjectCount() != 0 {
+		t.Fatalf("idle object should be destructed at once, destructed:%d", fixture.destructed_count())
+	}
+
+	if !pool.InvalidateByID(borrowed.GetId()) {
+		t.Fatalf("borrowed object should be invalidated")
+	}
+	if fixture.destructed_count() != 1 {
+		t.Fatalf("borrowed object should not be destructed before return")
+	}
+	pool.ReturnObject(borrowed)
+	if fixture.destructed_count() != 2 || pool.GetObjectCount() != 0 {
+		t.Fatalf("invalidated object should be destructed on return, destructed:%d", fixture.destructed_count())
+	}
+	if pool.InvalidateByID(borrowed.GetId()) {
+		t.Fatalf("destructed object should not be found")
+	}
+}
+
+func TestDuplicateId(t *testing.T) {
+	shared := &test_object{id: 0}
+	fixture := new_validator_fixture()
+	pool, err := New(func() (interface{}, error) {
+				return shared, nil
+			},
+			WithDestructor(fixture.destruct),
+			WithIdExtractor(test_object_id_extractor))
+	if err != nil {
+		t.Fatalf("New() create pool failed, err:%v", err)
+	}
+	defer pool.Close()
+
+	object := get_object_and_check(t, pool)
+	if _, err := pool.GetObject(); !errors.Is(err, ErrDuplicateId) {
+		t.Fatalf("constructor returned a pooled object, err:%v", err)
+	}
+	if fixture.destructed_count() != 0 {
+		t.Fatalf("duplicate object should not be destructed")
+	}
+	stats := pool.Stats()
+	if stats.ConstructFailureCount != 1 || stats.ObjectCount != 1 {
+		t.Fatalf("stats mismatch, stats:%+v", stats)
+	}
+	pool.ReturnObject(object)
+}
+
+func TestDefaultId_EqualObjects(t *testing.T) {
+	for name, constructor := range map[string]Constructor{
+		"value":     func() (interface{}, error) { return 7, nil },
+		"zero_size": func() (interface{}, error) { return new(struct{}), nil },
+	} {
+		pool, err := New(constructor)
+		if err != nil {
+			t.Fatalf("New() create pool failed, err:%v", err)
+		}
+		first, err := pool.GetObject()
+		if err != nil {
+			t.Fatalf("%s: GetObject() failed, err:%s", name, err)
+		}
+		second, err := pool.GetObject()
+		if err != nil {
+			t.Fatalf("%s: equal objects should not be duplicates, err:%s", name, err)
+		}
+		if first.GetId() == second.GetId() {
+			t.Fatalf("%s: default ids should differ, id:%s", name, first.GetId())
+		}
+		if info, has := pool.Lookup(second.GetId()); !has || !info.Borrowed {
+			t.Fatalf("%s: default id should be found, has:%t", name, has)
+		}
+		pool.Close()
+	}
+}
+
+func TestLookup_DestroyedObject(t *testing.T) {
+	pool := new_lookup_test_pool(t, new_validator_fixture())
+	defer pool.Close()
+
+	object := get_object_and_check(t, pool)
+	object.MarkUnusable()
+	pool.ReturnObject(object)
+	if _, has := pool.Lookup(object.GetId()); has {
+		t.Fatalf("destroyed object should leave the index")
+	}
+}
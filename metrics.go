@@ -0,0 +1,168 @@
+package ObjectPool
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const openMetricsContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+
+// StatsProvider is any pool MetricsHandler can render, e.g. *BasicPool or
+// *Pool[T].
+type StatsProvider interface {
+	Stats() Stats
+}
+
+// MetricsHandler serves Stats() of named pools in the OpenMetrics text
+// format, so Prometheus can scrape them without a client library.
+type MetricsHandler struct {
+	mutex sync.Mutex
+	pools map[string]StatsProvider
+}
+
+func NewMetricsHandler() *MetricsHandler {
+	return &MetricsHandler{pools: make(map[string]StatsProvider)}
+}
+
+// Register adds pool under name, replacing any pool registered before.
+func (h *MetricsHandler) Register(name string, pool StatsProvider) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.pools[name] = pool
+}
+
+func (h *MetricsHandler) Unregister(name string) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	delete(h.pools, name)
+}
+
+func (h *MetricsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", openMetricsContentType)
+	h.WriteMetrics(w)
+}
+
+type namedStats struct {
+	name  string
+	stats Stats
+}
+
+type metricFamily struct {
+	name       string
+	metricType string
+	help       string
+	value      func(Stats) float64
+}
+
+var gaugeFamilies = []metricFamily{
+	{"objectpool_idle_objects", "gauge", "Objects idle in the pool.",
+		func(s Stats) float64 { return float64(s.IdleObjectCount) }},
+	{"objectpool_active_objects", "gauge", "Objects lent to borrowers or being constructed.",
+		func(s Stats) float64 { return float64(s.ActiveObjectCount) }},
+	{"objectpool_objects", "gauge", "Objects owned by the pool.",
+		func(s Stats) float64 { return float64(s.ObjectCount) }},
+	{"objectpool_waiters", "gauge", "Borrowers waiting for an object.",
+		func(s Stats) float64 { return float64(s.WaiterCount) }},
+	{"objectpool_max_objects", "gauge", "Maximum number of objects, +Inf if unlimited.",
+		func(s Stats) float64 {
+			if s.MaxObjectCount == UnlimitedObjectCount {
+				return math.Inf(1)
+			}
+			return float64(s.MaxObjectCount)
+		}},
+	{"objectpool_min_objects", "gauge", "Minimum number of objects.",
+		func(s Stats) float64 { return float64(s.MinObjectCount) }},
+	{"objectpool_created_objects", "counter", "Objects constructed.",
+		func(s Stats) float64 { return float64(s.CreatedCount) }},
+	{"objectpool_destroyed_objects", "counter", "Objects destructed.",
+		func(s Stats) float64 { return float64(s.DestroyedCount) }},
+	{"objectpool_construct_failures", "counter", "Failed constructions.",
+		func(s Stats) float64 { return float64(s.ConstructFailureCount) }},
+	{"objectpool_evicted_objects", "counter", "Idle objects destroyed by eviction.",
+		func(s Stats) float64 { return float64(s.EvictedCount) }},
+	{"objectpool_waits", "counter", "Borrows that had to wait for an object.",
+		func(s Stats) float64 { return float64(s.WaitCount) }},
+	{"objectpool_wait_duration_seconds", "counter", "Total time borrowers waited for an object.",
+		func(s Stats) float64 { return s.WaitDuration.Seconds() }},
+}
+
+type histogramFamily struct {
+	name      string
+	help      string
+	histogram func(Stats) DurationHistogram
+}
+
+var histogramFamilies = []histogramFamily{
+	{"objectpool_borrow_wait_seconds", "Time spent in GetObject by successful borrowers.",
+		func(s Stats) DurationHistogram { return s.BorrowWaitHistogram }},
+	{"objectpool_hold_seconds", "Time objects were held before they were returned.",
+		func(s Stats) DurationHistogram { return s.HoldHistogram }},
+}
+
+// WriteMetrics writes the exposition of every registered pool to w.
+func (h *MetricsHandler) WriteMetrics(w io.Writer) error {
+	h.mutex.Lock()
+	pools := make([]namedStats, 0, len(h.pools))
+	for name, pool := range h.pools {
+		pools = append(pools, namedStats{name: name, stats: pool.Stats()})
+	}
+	h.mutex.Unlock()
+	sort.Slice(pools, func(i, j int) bool {
+		return pools[i].name < pools[j].name
+	})
+
+	bw := bufio.NewWriter(w)
+
+	// samples of one family must not be interleaved with other families.
+	for _, family := range gaugeFamilies {
+		fmt.Fprintf(bw, "# TYPE %s %s\n", family.name, family.metricType)
+		fmt.Fprintf(bw, "# HELP %s %s\n", family.name, family.help)
+		suffix := ""
+		if family.metricType == "counter" {
+			suffix = "_total"
+		}
+		for _, pool := range pools {
+			fmt.Fprintf(bw, "%s%s{pool=\"%s\"} %s\n", family.name, suffix,
+				escapeLabelValue(pool.name), formatFloat(family.value(pool.stats)))
+		}
+	}
+
+	for _, family := range histogramFamilies {
+		fmt.Fprintf(bw, "# TYPE %s histogram\n", family.name)
+		fmt.Fprintf(bw, "# HELP %s %s\n", family.name, family.help)
+		for _, pool := range pools {
+			label := escapeLabelValue(pool.name)
+			histogram := family.histogram(pool.stats)
+			for idx, bound := range histogram.Bounds {
+				fmt.Fprintf(bw, "%s_bucket{pool=\"%s\",le=\"%s\"} %d\n",
+					family.name, label, formatFloat(bound.Seconds()), histogram.Counts[idx])
+			}
+			fmt.Fprintf(bw, "%s_bucket{pool=\"%s\",le=\"+Inf\"} %d\n", family.name, label, histogram.Count)
+			fmt.Fprintf(bw, "%s_count{pool=\"%s\"} %d\n", family.name, label, histogram.Count)
+			fmt.Fprintf(bw, "%s_sum{pool=\"%s\"} %s\n", family.name, label, formatFloat(histogram.Sum.Seconds()))
+		}
+	}
+
+	fmt.Fprint(bw, "# EOF\n")
+	return bw.Flush()
+}
+
+func formatFloat(value float64) string {
+	if math.IsInf(value, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabelValue(value string) string {
+	return labelValueEscaper.Replace(value)
+}
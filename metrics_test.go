@@ -0,0 +1,92 @@
+package ObjectPool
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMetrics_Exposition(t *testing.T) {
+	first := new_test_object_pool(t, 1, 10)
+	defer first.Close()
+	second, err := New(test_object_constructor)
+	if err != nil {
+		t.Fatalf("New() create pool failed, err:%v", err)
+	}
+	defer second.Close()
+
+	object_holder := get_object_and_check(t, first)
+	time.Sleep(2 * time.Millisecond)
+	first.ReturnObject(object_holder)
+	get_object_and_check(t, first)
+
+	handler := NewMetricsHandler()
+	handler.Register("first", first)
+	handler.Register("sec\"ond", second)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+
+	if content_type := recorder.Header().Get("Content-Type"); !strings.HasPrefix(content_type, "application/openmetrics-text") {
+		t.Fatalf("invalid content type:%s", content_type)
+	}
+
+	body := recorder.Body.String()
+	if !strings.HasSuffix(body, "# EOF\n") {
+		t.Fatalf("exposition should end with EOF, body:%s", body)
+	}
+
+	expects := []string{
+		"# TYPE objectpool_idle_objects gauge\n",
+		`objectpool_active_objects{pool="first"} 1` + "\n",
+		`objectpool_max_objects{pool="first"} 10` + "\n",
+		`objectpool_max_objects{pool="sec\"ond"} +Inf` + "\n",
+		"# TYPE objectpool_created_objects counter\n",
+		`objectpool_created_objects_total{pool="first"} 1` + "\n",
+		"# TYPE objectpool_hold_seconds histogram\n",
+		`objectpool_hold_seconds_bucket{pool="first",le="+Inf"} 1` + "\n",
+		`objectpool_hold_seconds_bucket{pool="first",le="0.0001"} 0` + "\n",
+		`objectpool_hold_seconds_count{pool="first"} 1` + "\n",
+		`objectpool_borrow_wait_seconds_count{pool="first"} 2` + "\n",
+		`objectpool_borrow_wait_seconds_count{pool="sec\"ond"} 0` + "\n",
+	}
+	for _, expect := range expects {
+		if !strings.Contains(body, expect) {
+			t.Errorf("exposition should contain:%q", expect)
+		}
+	}
+
+	// samples of a family are grouped under its TYPE line.
+	families := 0
+	for _, line := range strings.Split(body, "\n") {
+		if strings.HasPrefix(line, "# TYPE ") {
+			families += 1
+		}
+	}
+	if families != len(gaugeFamilies) + len(histogramFamilies) {
+		t.Fatalf("each family should be declared once, get:%d", families)
+	}
+
+	handler.Unregister("first")
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	if strings.Contains(recorder.Body.String(), `pool="first"`) {
+		t.Fatalf("unregistered pool should not be exposed")
+	}
+}
+
+func TestMetrics_HistogramCumulative(t *testing.T) {
+	histogram := newDurationHistogram([]time.Duration{time.Millisecond, time.Second})
+	histogram.observe(time.Microsecond)
+	histogram.observe(10 * time.Millisecond)
+	histogram.observe(time.Minute)
+
+	snapshot := histogram.snapshot()
+	if snapshot.Counts[0] != 1 || snapshot.Counts[1] != 2 || snapshot.Count != 3 {
+		t.Fatalf("buckets should be cumulative, counts:%v, count:%d", snapshot.Counts, snapshot.Count)
+	}
+	if snapshot.Sum != time.Microsecond + 10 * time.Millisecond + time.Minute {
+		t.Fatalf("invalid sum:%s", snapshot.Sum)
+	}
+}
@@ -1,32 +1,106 @@
 package ObjectPool
 
-import "time"
+import (
+	"sync/atomic"
+	"time"
+)
 
-type objectHolder struct {
+// holder states, they let borrow and return skip the pool mutex.
+const (
+	// owned by the pool: idle in idlePool, being constructed, validated or
+	// handed to a waiter.
+	holderIdle int32 = iota
+	holderBorrowed
+	// between the lock-free return and the push onto idleStack.
+	holderReturning
+	// on idleStack.
+	holderParked
+	// destructed by a forced Shutdown(), it must not be lent anymore.
+	holderClosed
+)
+
+// ObjectHolder wraps an object lent by an ObjectPool.
+type ObjectHolder struct {
 	object      interface{}
+	// IdExtractor result, empty while the object is being constructed.
+	id          string
 	createTime  time.Time
-	lastUseTime time.Time
-	useCount    uint64
-	usable      bool
+	// unix nanos, updated without the pool mutex.
+	lastUseTime atomic.Int64
+	useCount    atomic.Uint64
+	usable      atomic.Bool
+	// zero if the object never expires.
+	expireTime  time.Time
+	// when and where the current borrower got it, zero if not borrowed. The
+	// stack is only recorded with leak detection.
+	borrowTime   atomic.Int64
+	borrowStack  []uintptr
+	leakReported bool
+	// set by InvalidateByID(), destructs the object once returned.
+	invalidated  atomic.Bool
+	// the pool that lent it, nil for NewObjectHolder().
+	owner        *BasicPool
+	state        atomic.Int32
+	// idleStack slot index+1, and the slot index+1 below it on the stack.
+	slot         uint32
+	next         atomic.Uint32
+}
+
+// NewObjectHolder wraps object in a usable holder, it lets ObjectPool
+// implementations outside this package, e.g. mocks in tests, lend objects.
+func NewObjectHolder(object interface{}) *ObjectHolder {
+	now := time.Now()
+	holder := &ObjectHolder{
+		object:     object,
+		createTime: now,
+	}
+	holder.lastUseTime.Store(now.UnixNano())
+	holder.useCount.Store(1)
+	holder.usable.Store(true)
+	return holder
 }
 
-func (o objectHolder) ExtractObject() interface{} {
+func (o *ObjectHolder) ExtractObject() interface{} {
 	return o.object
 }
 
-func (o objectHolder) GetCreateTime() time.Time {
+func (o *ObjectHolder) GetId() string {
+	return o.id
+}
+
+func (o *ObjectHolder) GetCreateTime() time.Time {
 	return o.createTime
 }
 
-func (o objectHolder) GetUseCount() uint64 {
-	return o.useCount
+func (o *ObjectHolder) GetUseCount() uint64 {
+	return o.useCount.Load()
 }
 
-func (o objectHolder) IsUsable() bool {
-	return o.usable
+func (o *ObjectHolder) IsUsable() bool {
+	return o.usable.Load()
 }
 
-func (o *objectHolder) MarkUnusable() {
-	o.usable = false
+func (o *ObjectHolder) MarkUnusable() {
+	o.usable.Store(false)
 }
 
+
+func (o *ObjectHolder) expired(now time.Time) bool {
+	return !o.expireTime.IsZero() && !now.Before(o.expireTime)
+}
+
+func (o *ObjectHolder) lastUse() time.Time {
+	return nanoTime(o.lastUseTime.Load())
+}
+
+func (o *ObjectHolder) borrowedAt() time.Time {
+	return nanoTime(o.borrowTime.Load())
+}
+
+// nanoTime turns unix nanos back into a time, zero stays the zero time.
+func nanoTime(nanos int64) time.Time {
+	if nanos == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nanos)
+}
@@ -1,28 +1,84 @@
 package ObjectPool
 
 import (
+	"context"
 	"errors"
+	"log/slog"
+	"math/rand"
 	"time"
 	"sync"
+	"sync/atomic"
     "fmt"
 )
 
 type Constructor func() (interface{}, error)
+// ContextConstructor gets the context of the borrower, limited by
+// Config.ConstructTimeout.
+type ContextConstructor func(ctx context.Context) (interface{}, error)
 type Destructor func(interface{})
 type IdExtractor func(interface{}) string
 
 var (
 	ErrIsClosed = errors.New("object pool is closed")
     ErrNotExists = errors.New("object is not exist in the pool")
+	ErrReachMaxLimit = errors.New("reach max object count limits")
+	ErrDuplicateId = errors.New("object id already exists in the pool")
 )
 
-type objectPool struct {
-	constructor 	Constructor
+// ObjectPool is the abstraction over a pool of objects, NewObjectPool returns
+// an implementation of it.
+type ObjectPool interface {
+	GetObject() (*ObjectHolder, error)
+	GetObjectContext(ctx context.Context) (*ObjectHolder, error)
+	ReturnObject(object *ObjectHolder) error
+	Shutdown(ctx context.Context) error
+	Close()
+
+	IsClosed() bool
+	GetObjectCount() uint32
+	GetIdleObjectCount() uint32
+	GetMaxObjectCount() uint32
+	GetMinObjectCount() uint32
+	GetIdleTime() time.Duration
+	Stats() Stats
+}
+
+var _ ObjectPool = (*BasicPool)(nil)
+
+// objectRequest is what a parked GetObjectContext() caller receives, either
+// an object ready for use or, if fresh is set, a reserved slot it should fill
+// by calling the constructor itself.
+type objectRequest struct {
+	holder *ObjectHolder
+	fresh  bool
+}
+
+// borrowMode tells acquireObject what to do when no object is idle.
+type borrowMode int
+
+const (
+	// create an object, fail with ErrReachMaxLimit if the pool is exhausted.
+	borrowNoWait borrowMode = iota
+	// create an object, wait if the pool is exhausted.
+	borrowWait
+	// fail with ErrReachMaxLimit, ShardedPool steals idle objects with it.
+	borrowIdleOnly
+)
+
+// BasicPool is the ObjectPool NewObjectPool and New return, on top of the
+// interface it can be resized, inspected and evicted at runtime.
+type BasicPool struct {
+	constructor 	ContextConstructor
 	destructor 		Destructor
 	idExtractor 	IdExtractor
-
-	idlePool 		[]*objectHolder
-	activePool		map[*objectHolder]bool
+	listener		Listener
+	logger			*slog.Logger
+	config			Config
+
+	idlePool 		[]*ObjectHolder
+	activePool		map[*ObjectHolder]bool
+	// constructed objects by id, an object leaves it once destructed.
+	objects			map[string]*ObjectHolder
 	closed        	bool
 	maxObjectCount 	uint32
 	minObjectCount 	uint32
@@ -30,9 +86,43 @@ type objectPool struct {
 	decreaseStep	uint32
 
 	mutex			sync.Mutex
-
-	destructQueue 	chan *objectHolder
-	finishSignal 	chan bool
+	waitQueue		waitQueue
+
+	destructQueue 	chan *ObjectHolder
+	// one destructor per destructQueue, resizing replaces the queue.
+	destructors		sync.WaitGroup
+
+	// closed by Shutdown() to stop background goroutines.
+	stopSignal		chan struct{}
+	// closed once the pool is closed and no object is borrowed anymore.
+	drained			chan struct{}
+	background		sync.WaitGroup
+	// nil unless Config.Replenish is set.
+	replenishSignal	chan struct{}
+	// shared by the sub-pools of a KeyedPool, limiterHeld is the number of
+	// slots taken from it.
+	limiter			*capacityLimiter
+	limiterHeld		int
+
+	// lock-free borrow and return, see idle_stack.go. Parked objects stay in
+	// activePool, the atomics mirror state the fast path must not miss.
+	idleStack		idleStack
+	fastPath		bool
+	closing			atomic.Bool
+	waiters			atomic.Int32
+	overMax			atomic.Bool
+
+	// lifetime counters reported by Stats(), guarded by mutex.
+	createdCount			uint64
+	destroyedCount			uint64
+	constructFailureCount	uint64
+	evictedCount			uint64
+	waitCount				uint64
+	waitDuration			time.Duration
+
+	// time spent in GetObject() and time objects were held by borrowers.
+	waitHistogram			*durationHistogram
+	holdHistogram			*durationHistogram
 }
 
 func NewObjectPool(
@@ -41,107 +131,509 @@ func NewObjectPool(
 		idle_time 	time.Duration,
 		constructor Constructor,
 		destructor 	Destructor,
-		idExtractor IdExtractor) (*objectPool, error) {
+		idExtractor IdExtractor,
+		opts 		...Option) (*BasicPool, error) {
 
-	if max_object != 0 && min_object > max_object {
-		return nil, fmt.Errorf("min_object should lower or equal to max_object, max_object:%d, min_object:%d", max_object, min_object)
+	if idExtractor == nil {
+		return nil, errors.New("need parameter idExtractor")
 	}
 
-	if constructor == nil {
-		return nil, errors.New("need parameter constructor")
+	config := DefaultConfig()
+	config.MinObjectCount = min_object
+	config.MaxObjectCount = max_object
+	config.IdleTime = idle_time
+	config.Destructor = destructor
+	config.IdExtractor = idExtractor
+	for _, opt := range opts {
+		opt(&config)
 	}
 
-	if destructor == nil {
-		return nil, errors.New("need parameter destructor")
+	return NewWithConfig(constructor, config)
+}
+
+// New creates a pool from DefaultConfig() adjusted by opts.
+func New(constructor Constructor, opts ...Option) (*BasicPool, error) {
+	config := DefaultConfig()
+	for _, opt := range opts {
+		opt(&config)
 	}
 
-	if idExtractor == nil {
-		return nil, errors.New("need parameter idExtractor")
+	return NewWithConfig(constructor, config)
+}
+
+func NewWithConfig(constructor Constructor, config Config) (*BasicPool, error) {
+	return newObjectPool(contextConstructor(constructor, config), config, nil)
+}
+
+// NewWithContextConstructor works like New, but constructor gets the context
+// of the borrower.
+func NewWithContextConstructor(constructor ContextConstructor, opts ...Option) (*BasicPool, error) {
+	config := DefaultConfig()
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	return newObjectPool(constructor, config, nil)
+}
+
+func newObjectPool(constructor ContextConstructor, config Config, limiter *capacityLimiter) (*BasicPool, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
 	}
 
-	pool := &objectPool {
+	if constructor == nil {
+		return nil, errors.New("need parameter constructor")
+	}
+
+	pool := &BasicPool {
 		constructor:	constructor,
-		destructor:		destructor,
-		idExtractor:	idExtractor,
+		destructor:		config.Destructor,
+		idExtractor:	config.IdExtractor,
+		config:			config,
 		closed: false,
-		maxObjectCount: max_object,
-		minObjectCount: min_object,
-		idleTime: idle_time,
+		maxObjectCount: config.MaxObjectCount,
+		minObjectCount: config.MinObjectCount,
+		idleTime: config.IdleTime,
+		limiter: limiter,
+		listener: config.Listener,
+		logger: newPoolLogger(config),
+	}
+	if pool.listener == nil {
+		pool.listener = NopListener{}
 	}
 
-    decreaseStep := uint32(0)
-    if max_object != 0 {
-        decreaseStep = max_object - min_object
-    }
-	decreaseStep = decreaseStep / 20
-    if decreaseStep < 10 {
-        decreaseStep = 10
-    }
-
+	decreaseStep := config.decreaseStep()
 	pool.decreaseStep = decreaseStep
 
-	pool.destructQueue = make(chan*objectHolder, decreaseStep * 2)
-	pool.finishSignal = make(chan bool, 1)
-    pool.activePool = make(map[*objectHolder]bool)
+	pool.destructQueue = make(chan*ObjectHolder, decreaseStep * 2)
+	pool.waitQueue.aging = config.PriorityAging
+	// lock-free returns leave idle objects to the evictor.
+	pool.fastPath = config.BorrowStrategy == BorrowLIFO && config.LeakDetectionThreshold == 0 &&
+		config.EvictionInterval > 0
+    pool.activePool = make(map[*ObjectHolder]bool)
+	pool.objects = make(map[string]*ObjectHolder)
+	pool.stopSignal = make(chan struct{})
+	pool.drained = make(chan struct{})
+	pool.waitHistogram = newDurationHistogram(defaultHistogramBounds)
+	pool.holdHistogram = newDurationHistogram(defaultHistogramBounds)
+
+	pool.destructors.Add(1)
+	go pool.idleObjectDestructor(pool.destructQueue)
+
+	if config.Prefill {
+		failures, err := pool.fillMinObjects()
+		if failures > config.PrefillMaxFailures {
+			pool.Close()
+			return nil, fmt.Errorf("prefill object pool failed, failures:%d, constructor_error:%s", failures, err)
+		}
+	}
+
+	if config.Replenish {
+		pool.replenishSignal = make(chan struct{}, 1)
+		pool.background.Add(1)
+		go pool.replenisher()
+	}
+
+	if config.EvictionInterval > 0 {
+		pool.background.Add(1)
+		go pool.idleObjectEvictor(config.EvictionInterval)
+	}
 
-	go pool.idleObjectDestructor()
+	if config.LeakDetectionThreshold > 0 {
+		pool.background.Add(1)
+		go pool.leakDetector()
+	}
 
 	return pool, nil
 }
 
-func (p *objectPool) GetObject() (*objectHolder, error) {
+// GetObject returns an idle object or creates a new one, it fails with
+// ErrReachMaxLimit immediately when the pool is exhausted.
+func (p *BasicPool) GetObject() (*ObjectHolder, error) {
+	return p.getObject(context.Background(), borrowNoWait, 0)
+}
+
+// GetObjectContext works like GetObject, but when the pool is exhausted the
+// caller is parked in a FIFO wait queue until an object is returned or
+// capacity is freed, or until ctx is done.
+func (p *BasicPool) GetObjectContext(ctx context.Context) (*ObjectHolder, error) {
+	return p.getObject(ctx, borrowWait, 0)
+}
+
+// GetObjectWithPriority works like GetObjectContext, but when the pool is
+// exhausted callers with a higher priority are served first, see
+// Config.PriorityAging. GetObjectContext waits with priority 0.
+func (p *BasicPool) GetObjectWithPriority(ctx context.Context, priority int) (*ObjectHolder, error) {
+	return p.getObject(ctx, borrowWait, priority)
+}
+
+func (p *BasicPool) getObject(ctx context.Context, mode borrowMode, priority int) (*ObjectHolder, error) {
+	start := time.Now()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	object := p.borrowParked()
+	if object == nil {
+		var err error
+		object, err = p.borrowObject(ctx, mode, priority)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	p.waitHistogram.observe(now.Sub(start))
+	p.logSlow("borrow", object.id, now.Sub(start))
+
+	if p.config.LeakDetectionThreshold > 0 {
+		stack := borrowerStack()
+		p.mutex.Lock()
+		object.borrowStack = stack
+		object.leakReported = false
+		p.mutex.Unlock()
+	}
+	object.borrowTime.Store(now.UnixNano())
+	// a forced Shutdown() destructed it meanwhile.
+	if !object.state.CompareAndSwap(holderIdle, holderBorrowed) {
+		return nil, ErrIsClosed
+	}
+
+	p.listener.OnBorrow(object.info())
+
+	return object, nil
+}
+
+func (p *BasicPool) borrowObject(ctx context.Context, mode borrowMode, priority int) (*ObjectHolder, error) {
+	for {
+		request, err := p.acquireObject(ctx, mode, priority)
+		if err != nil {
+			return nil, err
+		}
+
+		if request.fresh {
+			return p.constructObject(ctx, request.holder)
+		}
+
+		// expired, invalidated or broken idle object, destroy it and try the
+		// next one.
+		if request.holder.expired(time.Now()) || request.holder.invalidated.Load() {
+			p.destroyActiveObject(request.holder)
+			continue
+		}
+		if p.config.BorrowValidator != nil && p.config.BorrowValidator(request.holder.object) != nil {
+			p.destroyActiveObject(request.holder)
+			continue
+		}
+
+		request.holder.useCount.Add(1)
+		return request.holder, nil
+	}
+}
+
+// acquireObject takes an idle object or, depending on mode, reserves a slot
+// for a new one or parks the caller.
+func (p *BasicPool) acquireObject(ctx context.Context, mode borrowMode, priority int) (objectRequest, error) {
+	if err := ctx.Err(); err != nil {
+		return objectRequest{}, err
+	}
+
 	p.mutex.Lock()
 
 	if p.closed {
 		p.mutex.Unlock()
-		return nil, ErrIsClosed
+		return objectRequest{}, ErrIsClosed
+	}
+
+	// parked objects were used last, LIFO takes them first.
+	if object := p.popParked(); object != nil {
+		p.mutex.Unlock()
+		return objectRequest{holder: object}, nil
 	}
 
-	objectIdle := len(p.idlePool)
-	if objectIdle > 0 {
-		object := p.idlePool[objectIdle-1]
-		p.idlePool = p.idlePool[:objectIdle-1]
+	if len(p.idlePool) > 0 {
+		object := p.takeIdleLocked()
 		p.activePool[object] = true
 		p.mutex.Unlock()
-        object.useCount += 1
-		return object, nil
+		return objectRequest{holder: object}, nil
 	}
 
-	objectActive := len(p.activePool)
+	var object *ObjectHolder
+	if p.waitQueue.Len() == 0 && mode != borrowIdleOnly {
+		object = p.tryReserveLocked()
+	}
+	if object == nil {
+		if mode != borrowWait {
+			p.mutex.Unlock()
+			return objectRequest{}, ErrReachMaxLimit
+		}
+		return p.waitObject(ctx, priority)
+	}
+	p.mutex.Unlock()
 
-	if objectActive + objectIdle > int(p.maxObjectCount) {
+	return objectRequest{holder: object, fresh: true}, nil
+}
+
+// takeIdleLocked removes the idle object BorrowStrategy picks, idlePool stays
+// ordered by lastUseTime.
+func (p *BasicPool) takeIdleLocked() *ObjectHolder {
+	idx := len(p.idlePool) - 1
+	switch p.config.BorrowStrategy {
+	case BorrowFIFO:
+		idx = 0
+	case BorrowRandom:
+		idx = rand.Intn(len(p.idlePool))
+	}
+
+	object := p.idlePool[idx]
+	copy(p.idlePool[idx:], p.idlePool[idx+1:])
+	p.idlePool[len(p.idlePool)-1] = nil
+	p.idlePool = p.idlePool[:len(p.idlePool)-1]
+	return object
+}
+
+// waitObject parks the caller until an object is handed over, must be called
+// with mutex held and releases it.
+func (p *BasicPool) waitObject(ctx context.Context, priority int) (objectRequest, error) {
+	waiter := &objectWaiter{ready: make(chan objectRequest, 1), priority: priority}
+	p.waitQueue.enqueue(waiter)
+	p.waiters.Store(int32(p.waitQueue.Len()))
+	p.waitCount += 1
+	// an object parked right before the waiter showed up.
+	p.flushParkedLocked()
+	p.mutex.Unlock()
+
+	waitStart := time.Now()
+	select {
+	case request, ok := <-waiter.ready:
+		p.addWaitDuration(time.Since(waitStart))
+		if !ok {
+			return objectRequest{}, ErrIsClosed
+		}
+		return request, nil
+	case <-ctx.Done():
+		p.mutex.Lock()
+		removed := p.removeWaiterLocked(waiter)
+		p.waitDuration += time.Since(waitStart)
 		p.mutex.Unlock()
-		return nil, errors.New("reach max object count limits")
+		if !removed {
+			// lost the race with a hand over, give it back.
+			if request, ok := <-waiter.ready; ok {
+				p.rejectRequest(request)
+			}
+		}
+		return objectRequest{}, ctx.Err()
 	}
+}
 
-	object := &objectHolder{}
-	p.activePool[object] = true
-	object.useCount = 1
-	object.usable = true
-	object.lastUseTime = time.Now()
-	object.createTime = object.lastUseTime
+func (p *BasicPool) addWaitDuration(duration time.Duration) {
+	p.mutex.Lock()
+	p.waitDuration += duration
+	p.mutex.Unlock()
+}
 
+// destroyActiveObject drops a lent object the borrower will never return.
+func (p *BasicPool) destroyActiveObject(object *ObjectHolder) {
+	p.mutex.Lock()
+	// a forced shutdown destructed it already.
+	_, has := p.activePool[object]
+	delete(p.activePool, object)
+	if has {
+		p.destroyedCount += 1
+	}
+	p.releaseSlotLocked()
 	p.mutex.Unlock()
 
-	inner_object, cons_err := p.constructor()
+	if has {
+		p.destroyObject(object)
+	}
+}
+
+func (p *BasicPool) rejectRequest(request objectRequest) {
+	p.mutex.Lock()
+	if !request.fresh {
+		if p.closed {
+			p.returnClosedLocked(request.holder)
+			return
+		}
+		p.returnObjectLocked(request.holder)
+		return
+	}
+	delete(p.activePool, request.holder)
+	p.releaseSlotLocked()
+	p.mutex.Unlock()
+}
+
+func (p *BasicPool) constructObject(ctx context.Context, object *ObjectHolder) (*ObjectHolder, error) {
+	start := time.Now()
+	inner_object, cons_err := p.construct(ctx)
+	duration := time.Since(start)
 	if cons_err != nil {
 		p.mutex.Lock()
 		delete(p.activePool, object)
+		p.constructFailureCount += 1
+		p.releaseSlotLocked()
 		p.mutex.Unlock()
-		return nil, fmt.Errorf("create new object failed, constructor_error:%s", cons_err)
+		err := fmt.Errorf("create new object failed, constructor_error:%w", cons_err)
+		p.logger.Warn("create object failed",
+			slog.Any("error", cons_err),
+			slog.Duration("duration", duration))
+		p.listener.OnCreateFailed(err)
+		return nil, err
 	}
 
+	id := p.objectId(inner_object, object)
+
+	p.mutex.Lock()
+	if p.closed {
+		p.createdCount += 1
+		// Shutdown() could not destruct an object it did not see.
+		delete(p.activePool, object)
+		p.destroyedCount += 1
+		p.checkDrainedLocked()
+		p.mutex.Unlock()
+		object.object = inner_object
+		object.id = id
+		p.listener.OnCreate(object.info())
+		p.destroyObject(object)
+		return nil, ErrIsClosed
+	}
+	if p.idExtractor != nil && p.findLocked(id) != nil {
+		// the constructor handed out a pooled object again, it must not be
+		// destructed here.
+		delete(p.activePool, object)
+		p.constructFailureCount += 1
+		p.releaseSlotLocked()
+		p.mutex.Unlock()
+		err := fmt.Errorf("create new object failed, id:%s, err:%w", id, ErrDuplicateId)
+		p.logger.Error("constructor returned an object already in the pool",
+			slog.String("object_id", id))
+		p.listener.OnCreateFailed(err)
+		return nil, err
+	}
+	p.createdCount += 1
 	object.object = inner_object
+	object.id = id
+	p.objects[id] = object
+	info := object.info()
+	p.mutex.Unlock()
+
+	if p.fastPath {
+		p.idleStack.register(object)
+	}
+
+	p.logSlow("construct", id, duration)
+	p.listener.OnCreate(info)
 
 	return object, nil
 }
 
-func (p *objectPool) ReturnObject(object *objectHolder) error {
+// objectId uses IdExtractor if the caller supplied one, the default id is
+// derived from the holder, so equal objects get distinct ids.
+func (p *BasicPool) objectId(inner_object interface{}, object *ObjectHolder) string {
+	if p.idExtractor != nil {
+		return p.idExtractor(inner_object)
+	}
+	return fmt.Sprintf("%T_%p", inner_object, object)
+}
+
+func (p *BasicPool) reachMaxLocked() bool {
+	if p.maxObjectCount == UnlimitedObjectCount {
+		return false
+	}
+	return len(p.activePool) + len(p.idlePool) >= int(p.maxObjectCount)
+}
+
+func (p *BasicPool) reachMax() bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.reachMaxLocked()
+}
+
+// tryReserveLocked takes one slot of capacity with a holder that has not
+// been constructed yet, it returns nil if the pool or its limiter is
+// exhausted.
+func (p *BasicPool) tryReserveLocked() *ObjectHolder {
+	if p.reachMaxLocked() {
+		return nil
+	}
+	if p.limiter != nil {
+		if !p.limiter.acquire() {
+			return nil
+		}
+		p.limiterHeld += 1
+	}
+
+	object := &ObjectHolder{owner: p}
+	p.activePool[object] = true
+	object.useCount.Store(1)
+	object.usable.Store(true)
+	object.createTime = time.Now()
+	object.lastUseTime.Store(object.createTime.UnixNano())
+	object.expireTime = p.config.expireTime(object.createTime)
+	return object
+}
+
+// putIdleLocked hands a usable object to the waiter served next, or parks it
+// in idlePool if nobody is waiting.
+func (p *BasicPool) putIdleLocked(object *ObjectHolder) {
+	if p.waitQueue.Len() == 0 {
+		p.idlePool = append(p.idlePool, object)
+		if p.limiter != nil {
+			p.limiter.broadcast()
+		}
+		return
+	}
+	waiter := p.waitQueue.dequeue()
+	p.waiters.Store(int32(p.waitQueue.Len()))
+	p.activePool[object] = true
+	waiter.ready <- objectRequest{holder: object}
+}
+
+// releaseSlotLocked is called whenever an object left the pool, the freed
+// capacity goes to waiters, the replenisher or a pending Shutdown().
+func (p *BasicPool) releaseSlotLocked() {
+	p.syncLimiterLocked()
+	p.notifyWaiterLocked()
+	p.signalReplenishLocked()
+	p.checkDrainedLocked()
+}
+
+// notifyWaiterLocked hands freed capacity to waiting callers.
+func (p *BasicPool) notifyWaiterLocked() {
+	for p.waitQueue.Len() > 0 {
+		object := p.tryReserveLocked()
+		if object == nil {
+			return
+		}
+		waiter := p.waitQueue.dequeue()
+		p.waiters.Store(int32(p.waitQueue.Len()))
+		waiter.ready <- objectRequest{holder: object, fresh: true}
+	}
+}
+
+func (p *BasicPool) removeWaiterLocked(waiter *objectWaiter) bool {
+	if !p.waitQueue.remove(waiter) {
+		return false
+	}
+	p.waiters.Store(int32(p.waitQueue.Len()))
+	return true
+}
+
+func (p *BasicPool) ReturnObject(object *ObjectHolder) error {
+	if p.config.ReturnValidator != nil && object != nil && object.IsUsable() && p.lentBy(object) {
+		if p.config.ReturnValidator(object.object) != nil {
+			object.MarkUnusable()
+		}
+	}
+
+	if object != nil && p.returnParked(object) {
+		return nil
+	}
+
 	p.mutex.Lock()
 
 	if p.closed {
-		p.mutex.Unlock()
-		return ErrIsClosed
+		return p.returnClosedLocked(object)
 	}
 
     if object == nil {
@@ -149,15 +641,34 @@ func (p *objectPool) ReturnObject(object *objectHolder) error {
         return nil
     }
 
-    if _, has := p.activePool[object]; !has {
+	// returned twice, or a parked object.
+    if _, has := p.activePool[object]; !has || !object.state.CompareAndSwap(holderBorrowed, holderIdle) {
 		p.mutex.Unlock()
         return ErrNotExists
     }
 
+	return p.returnObjectLocked(object)
+}
+
+// returnObjectLocked takes back an object that is no longer lent, must be
+// called with mutex held and releases it.
+func (p *BasicPool) returnObjectLocked(object *ObjectHolder) error {
 	delete(p.activePool, object)
 
+	now := time.Now()
+	if borrowTime := object.borrowedAt(); !borrowTime.IsZero() {
+		p.holdHistogram.observe(now.Sub(borrowTime))
+	}
+	info := object.info()
+	info.Borrowed = false
+	// not borrowed anymore, keeps leak detection off it.
+	object.borrowTime.Store(0)
+	object.borrowStack = nil
+
 	allCount := len(p.idlePool) + len(p.activePool)
 
+	// evictions skipped because destructQueue is full.
+	dropped := false
 	if allCount > int(p.minObjectCount) {
 		decreaseCount := allCount - int(p.minObjectCount)
 		if int(p.decreaseStep)	< decreaseCount {
@@ -170,86 +681,140 @@ func (p *objectPool) ReturnObject(object *objectHolder) error {
         count := 0
 		CASUAL:
 		for ; count < decreaseCount; count += 1 {
-			if p.idlePool[count].lastUseTime.Add(p.idleTime).After(time.Now()) {
+			if p.idlePool[count].lastUse().Add(p.idleTime).After(time.Now()) {
 				break CASUAL
 			}
 			select {
 			case p.destructQueue <- p.idlePool[count]:
 			default:
+				dropped = true
 				break CASUAL
 			}
 		}
+		p.destroyedCount += uint64(count)
+		p.evictedCount += uint64(count)
 		copy(p.idlePool, p.idlePool[count:])
 		p.idlePool = p.idlePool[:len(p.idlePool)-count]
 		allCount = len(p.idlePool) + len(p.activePool)
 	}
 
 
-	if object.IsUsable() {
-		p.idlePool = append(p.idlePool, object)
+	// surplus after SetMaxObjectCount() shrank the pool.
+	if p.reusable(object, now) && !p.reachMaxLocked() {
+		object.lastUseTime.Store(now.UnixNano())
+		p.putIdleLocked(object)
+		// gives back what the shrinking above destroyed.
+		p.syncLimiterLocked()
+		p.updateOverMaxLocked()
 		p.mutex.Unlock()
+		p.listener.OnReturn(info)
 	} else {
+		p.destroyedCount += 1
+		p.releaseSlotLocked()
+		p.updateOverMaxLocked()
 		p.mutex.Unlock()
-		p.destructor(object.object)
+		p.listener.OnReturn(info)
+		if !info.Usable && !object.invalidated.Load() {
+			p.listener.OnMarkUnusable(info)
+		}
+		p.destroyObject(object)
+	}
+
+	if dropped {
+		p.logger.Warn("destruct queue is full, idle objects are left to the evictor",
+			slog.Int("queue_size", cap(p.destructQueue)))
 	}
 	return nil
 }
 
-func (p *objectPool) Close() {
-	p.mutex.Lock()
-	defer p.mutex.Unlock()
-
-	if p.closed {
-		return
+// returnClosedLocked destructs an object returned during Shutdown(), must be
+// called with mutex held and releases it.
+func (p *BasicPool) returnClosedLocked(object *ObjectHolder) error {
+	// force closed objects are gone already.
+	if _, has := p.activePool[object]; object == nil || !has {
+		p.mutex.Unlock()
+		return ErrIsClosed
 	}
 
-	p.closed = true
-	close(p.destructQueue)
+	delete(p.activePool, object)
+	p.destroyedCount += 1
+	p.checkDrainedLocked()
+	p.mutex.Unlock()
 
-	// must wait all object destructed.
-	for _, object := range p.idlePool {
-		p.destructor(object.object)
-	}
-	p.idlePool = []*objectHolder{}
+	p.destroyObject(object)
+	return nil
+}
 
-	for object, _ := range p.activePool {
-		p.destructor(object.object)
+// reusable tells whether a returned object may be lent again.
+func (p *BasicPool) reusable(object *ObjectHolder, now time.Time) bool {
+	if !object.IsUsable() || object.invalidated.Load() || object.expired(now) {
+		return false
 	}
-	p.activePool = map[*objectHolder]bool{}
+	return p.config.MaxUseCount == 0 || object.useCount.Load() < p.config.MaxUseCount
+}
 
-	<- p.finishSignal
+// lentBy tells without locking whether object is borrowed from p.
+func (p *BasicPool) lentBy(object *ObjectHolder) bool {
+	return object.owner == p && object.state.Load() == holderBorrowed && !p.closing.Load()
+}
+
+// Close destructs all objects at once, borrowed ones included, see
+// Shutdown() to wait for borrowers.
+func (p *BasicPool) Close() {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	p.Shutdown(ctx)
 }
 
 
-func (p *objectPool) idleObjectDestructor() {
-	for object := range p.destructQueue {
-		p.destructor(object.object)
+func (p *BasicPool) idleObjectDestructor(queue <-chan *ObjectHolder) {
+	defer p.destructors.Done()
+	for object := range queue {
+		p.evictObject(object, evictIdleTimeout)
 	}
-	p.finishSignal <- true
 }
 
 
 // Accessor
-func (p objectPool) IsClosed() bool {
+func (p *BasicPool) IsClosed() bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
 	return p.closed
 }
 
-func (p objectPool) GetObjectCount() uint32 {
+func (p *BasicPool) GetObjectCount() uint32 {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
 	return uint32(len(p.idlePool) + len(p.activePool))
 }
 
-func (p objectPool) GetIdleObjectCount() uint32 {
-	return uint32(len(p.idlePool))
+func (p *BasicPool) GetIdleObjectCount() uint32 {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	idle, _ := p.countObjectsLocked()
+	return idle
 }
 
-func (p objectPool) GetMaxObjectCount() uint32 {
+func (p *BasicPool) GetMaxObjectCount() uint32 {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
 	return p.maxObjectCount
 }
 
-func (p objectPool) GetMinObjectCount() uint32 {
+func (p *BasicPool) GetMinObjectCount() uint32 {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
 	return p.minObjectCount
 }
 
-func (p objectPool) GetIdleTime() time.Duration {
+func (p *BasicPool) GetIdleTime() time.Duration {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
 	return p.idleTime
 }
+
+func (p *BasicPool) Config() Config {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.config
+}
@@ -1,6 +1,8 @@
 package ObjectPool
 
 import (
+	"context"
+	"errors"
 	"io"
 	"sync"
 	"sync/atomic"
@@ -95,6 +97,33 @@ func conn_destructor(object interface{}) {
 	conn.Close()
 }
 
+// in-memory object for tests that do not need a real connection.
+type test_object struct {
+	id int32
+}
+
+var test_object_seq = int32(0)
+
+func test_object_constructor() (interface{}, error) {
+	return &test_object{id: atomic.AddInt32(&test_object_seq, 1)}, nil
+}
+
+func test_object_destructor(object interface{}) {
+}
+
+func test_object_id_extractor(object interface{}) string {
+	return fmt.Sprintf("test_object_%d", object.(*test_object).id)
+}
+
+func new_test_object_pool(t *testing.T, min_object uint32, max_object uint32) *BasicPool {
+	pool, err := NewObjectPool(min_object, max_object, idle_300s,
+					test_object_constructor, test_object_destructor, test_object_id_extractor)
+	if err != nil {
+		t.Fatalf("create pool failed, err:%s", err)
+	}
+	return pool
+}
+
 func TestNew_MinLowerToMax(t *testing.T) {
 	_, err := NewObjectPool(uint_1024, uint_512, idle_2s, 
 					conn_constructor, conn_destructor, conn_id_extractor)
@@ -295,13 +324,8 @@ func TestReturn_NotBelongToPool(t *testing.T) {
 	}
 	defer pool.Close()
 
-	object_holder := &objectHolder {
-		object: nil,
-		createTime: time.Now(),
-		lastUseTime: time.Now(),
-		useCount: 0,
-		usable: true,
-	}
+	object_holder := NewObjectHolder(nil)
+	object_holder.useCount.Store(0)
 
 	err = pool.ReturnObject(object_holder)
 	if err == nil {
@@ -360,6 +384,116 @@ func TestUseCount(t *testing.T) {
 }
 
 
+func TestUseCount_MaxUseCount(t *testing.T) {
+	pool, err := New(test_object_constructor, WithMaxUseCount(2))
+	if err != nil {
+		t.Fatalf("New() create pool failed, err:%v", err)
+	}
+	defer pool.Close()
+
+	first := get_object_and_check(t, pool)
+	pool.ReturnObject(first)
+	object_holder := get_object_and_check(t, pool)
+	if object_holder != first || object_holder.GetUseCount() != 2 {
+		t.Fatalf("object should be reused, use_count:%d", object_holder.GetUseCount())
+	}
+
+	pool.ReturnObject(object_holder)
+	if pool.GetObjectCount() != 0 {
+		t.Fatalf("object should be retired after max use count, object_count:%d", pool.GetObjectCount())
+	}
+
+	object_holder = get_object_and_check(t, pool)
+	if object_holder == first || object_holder.GetUseCount() != 1 {
+		t.Fatalf("retired object should be replaced, use_count:%d", object_holder.GetUseCount())
+	}
+}
+
+
+// returns count objects in creation order, so the first one is the least
+// recently returned.
+func return_in_order(t *testing.T, pool *BasicPool, count int) []*ObjectHolder {
+	object_holders := make([]*ObjectHolder, count)
+	for idx := 0; idx < count; idx += 1 {
+		object_holders[idx] = get_object_and_check(t, pool)
+	}
+	for _, object_holder := range object_holders {
+		pool.ReturnObject(object_holder)
+	}
+	return object_holders
+}
+
+func TestBorrowStrategy_LIFO(t *testing.T) {
+	pool := new_test_object_pool(t, 0, 10)
+	defer pool.Close()
+
+	object_holders := return_in_order(t, pool, 3)
+	for idx := 2; idx >= 0; idx -= 1 {
+		object_holder := get_object_and_check(t, pool)
+		if object_holder != object_holders[idx] {
+			t.Fatalf("LIFO should lend most recently returned first, expect:%d", idx)
+		}
+	}
+}
+
+func TestBorrowStrategy_FIFO(t *testing.T) {
+	pool, err := New(test_object_constructor, WithBorrowStrategy(BorrowFIFO))
+	if err != nil {
+		t.Fatalf("New() create pool failed, err:%v", err)
+	}
+	defer pool.Close()
+
+	object_holders := return_in_order(t, pool, 3)
+	for idx := 0; idx < 3; idx += 1 {
+		object_holder := get_object_and_check(t, pool)
+		if object_holder != object_holders[idx] {
+			t.Fatalf("FIFO should lend least recently returned first, expect:%d", idx)
+		}
+	}
+
+	// returned objects go to the back of the queue.
+	first := get_object_and_check(t, pool)
+	second := get_object_and_check(t, pool)
+	pool.ReturnObject(first)
+	pool.ReturnObject(second)
+	if get_object_and_check(t, pool) != first {
+		t.Fatalf("FIFO should rotate through objects")
+	}
+}
+
+func TestBorrowStrategy_Random(t *testing.T) {
+	pool, err := New(test_object_constructor, WithBorrowStrategy(BorrowRandom))
+	if err != nil {
+		t.Fatalf("New() create pool failed, err:%v", err)
+	}
+	defer pool.Close()
+
+	object_holders := return_in_order(t, pool, 3)
+	lent := make(map[*ObjectHolder]int)
+	for idx := 0; idx < 300; idx += 1 {
+		object_holder := get_object_and_check(t, pool)
+		lent[object_holder] += 1
+		pool.ReturnObject(object_holder)
+	}
+
+	for idx, object_holder := range object_holders {
+		if lent[object_holder] == 0 {
+			t.Fatalf("Random should lend every idle object, never lent:%d", idx)
+		}
+	}
+	if pool.GetObjectCount() != 3 {
+		t.Fatalf("GetObjectCount() expect:%d, get:%d", 3, pool.GetObjectCount())
+	}
+}
+
+func TestBorrowStrategy_Invalid(t *testing.T) {
+	_, err := New(test_object_constructor, WithBorrowStrategy(BorrowStrategy(-1)))
+	if err == nil {
+		t.Fatalf("New() should checking borrow strategy")
+	}
+}
+
+
 // Start multi goroutines, every routine first GetObject() & Write & Read & ReturnObject()
 // then sleep some time rand between [0ms, 10ms] and repeat the operation before.
 func TestCurrency(t *testing.T) {
@@ -432,7 +566,8 @@ func TestCurrency(t *testing.T) {
 			conn = nil
 			err = pool.ReturnObject(object)
             if err != nil {
-                t.Fatalf("return object failed, IDX:%d, err:%s", idx, err)
+                t.Errorf("return object failed, IDX:%d, err:%s", idx, err)
+                return
             }
 
 
@@ -516,6 +651,287 @@ func TestCurrency(t *testing.T) {
 }
 
 
+func TestGet_ReachMaxLimit(t *testing.T) {
+	pool := new_test_object_pool(t, 0, 2)
+	defer pool.Close()
+
+	get_object_and_check(t, pool)
+	get_object_and_check(t, pool)
+
+	object_holder, err := pool.GetObject()
+	if err != ErrReachMaxLimit {
+		t.Fatalf("GetObject() should fail with ErrReachMaxLimit, object:%v, err:%v", object_holder, err)
+	}
+
+	if pool.GetObjectCount() != 2 {
+		t.Fatalf("GetObjectCount() expect:%d, get:%d", 2, pool.GetObjectCount())
+	}
+}
+
+func TestGetContext_WaitReturn(t *testing.T) {
+	pool := new_test_object_pool(t, 0, 1)
+	defer pool.Close()
+
+	object_holder := get_object_and_check(t, pool)
+
+	result := make(chan *ObjectHolder, 1)
+	go func() {
+		waited, err := pool.GetObjectContext(context.Background())
+		if err != nil {
+			t.Errorf("GetObjectContext() failed, err:%s", err)
+		}
+		result <- waited
+	}()
+
+	select {
+	case <-result:
+		t.Fatalf("GetObjectContext() should wait while the pool is exhausted")
+	case <-time.After(idle_50ms):
+	}
+
+	err := pool.ReturnObject(object_holder)
+	if err != nil {
+		t.Fatalf("return object failed, err:%s", err)
+	}
+
+	waited := <-result
+	if waited != object_holder {
+		t.Fatalf("waiter should receive the returned object, expect:%p, get:%p", object_holder, waited)
+	}
+	if waited.GetUseCount() != 2 {
+		t.Fatalf("Invalid UseCount, expect:%d, get:%d", 2, waited.GetUseCount())
+	}
+}
+
+func TestGetContext_WaitUnusable(t *testing.T) {
+	pool := new_test_object_pool(t, 0, 1)
+	defer pool.Close()
+
+	object_holder := get_object_and_check(t, pool)
+
+	result := make(chan *ObjectHolder, 1)
+	go func() {
+		waited, err := pool.GetObjectContext(context.Background())
+		if err != nil {
+			t.Errorf("GetObjectContext() failed, err:%s", err)
+		}
+		result <- waited
+	}()
+	time.Sleep(idle_50ms)
+
+	object_holder.MarkUnusable()
+	pool.ReturnObject(object_holder)
+
+	waited := <-result
+	if waited == nil || waited == object_holder {
+		t.Fatalf("waiter should receive a new object, get:%p", waited)
+	}
+	if pool.GetObjectCount() != 1 {
+		t.Fatalf("GetObjectCount() expect:%d, get:%d", 1, pool.GetObjectCount())
+	}
+}
+
+func TestGetContext_Timeout(t *testing.T) {
+	pool := new_test_object_pool(t, 0, 1)
+	defer pool.Close()
+
+	object_holder := get_object_and_check(t, pool)
+
+	ctx, cancel := context.WithTimeout(context.Background(), idle_50ms)
+	defer cancel()
+	waited, err := pool.GetObjectContext(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("GetObjectContext() should time out, object:%v, err:%v", waited, err)
+	}
+
+	// the cancelled waiter must not steal the returned object.
+	pool.ReturnObject(object_holder)
+	if pool.GetIdleObjectCount() != 1 {
+		t.Fatalf("idle object count invalid, expect:%d, get:%d", 1, pool.GetIdleObjectCount())
+	}
+}
+
+func TestGetContext_FIFO(t *testing.T) {
+	pool := new_test_object_pool(t, 0, 1)
+	defer pool.Close()
+
+	object_holder := get_object_and_check(t, pool)
+
+	waiter_count := 5
+	order := make(chan int, waiter_count)
+	for idx := 0; idx < waiter_count; idx += 1 {
+		go func(idx int) {
+			waited, err := pool.GetObjectContext(context.Background())
+			if err != nil {
+				t.Errorf("GetObjectContext() failed, err:%s", err)
+				return
+			}
+			order <- idx
+			pool.ReturnObject(waited)
+		}(idx)
+		// make sure waiters are queued in order.
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	pool.ReturnObject(object_holder)
+	for idx := 0; idx < waiter_count; idx += 1 {
+		if get := <-order; get != idx {
+			t.Fatalf("waiters should be served in FIFO order, expect:%d, get:%d", idx, get)
+		}
+	}
+}
+
+func TestGetContext_Close(t *testing.T) {
+	pool := new_test_object_pool(t, 0, 1)
+
+	get_object_and_check(t, pool)
+
+	result := make(chan error, 1)
+	go func() {
+		_, err := pool.GetObjectContext(context.Background())
+		result <- err
+	}()
+	time.Sleep(idle_50ms)
+
+	pool.Close()
+	if err := <-result; err != ErrIsClosed {
+		t.Fatalf("waiter should fail with ErrIsClosed, get:%v", err)
+	}
+}
+
+
+// mock_pool shows ObjectPool can be swapped for another implementation.
+type mock_pool struct {
+	ObjectPool
+	object interface{}
+}
+
+func (p *mock_pool) GetObject() (*ObjectHolder, error) {
+	return NewObjectHolder(p.object), nil
+}
+
+func TestObjectPool_Mock(t *testing.T) {
+	var pool ObjectPool = &mock_pool{object: "mock"}
+
+	object_holder, err := pool.GetObject()
+	if err != nil {
+		t.Fatalf("GetObject() failed, err:%s", err)
+	}
+	if object_holder.ExtractObject() != "mock" || !object_holder.IsUsable() || object_holder.GetUseCount() != 1 {
+		t.Fatalf("invalid holder, object:%v, usable:%v, use_count:%d",
+			object_holder.ExtractObject(), object_holder.IsUsable(), object_holder.GetUseCount())
+	}
+
+	pool = new_test_object_pool(t, 0, 1)
+	defer pool.Close()
+	if pool.GetMaxObjectCount() != 1 {
+		t.Fatalf("GetMaxObjectCount() expect:%d, get:%d", 1, pool.GetMaxObjectCount())
+	}
+}
+
+
+// fails for objects in broken, and records destructed objects.
+type validator_fixture struct {
+	mutex      sync.Mutex
+	broken     map[interface{}]bool
+	destructed []interface{}
+}
+
+func new_validator_fixture() *validator_fixture {
+	return &validator_fixture{broken: make(map[interface{}]bool)}
+}
+
+func (f *validator_fixture) validate(object interface{}) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if f.broken[object] {
+		return errors.New("broken object")
+	}
+	return nil
+}
+
+func (f *validator_fixture) destruct(object interface{}) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.destructed = append(f.destructed, object)
+}
+
+func (f *validator_fixture) set_broken(object interface{}) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.broken[object] = true
+}
+
+func (f *validator_fixture) destructed_count() int {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return len(f.destructed)
+}
+
+func TestValidate_Borrow(t *testing.T) {
+	fixture := new_validator_fixture()
+	pool, err := New(test_object_constructor,
+			WithMaxObjects(2),
+			WithDestructor(fixture.destruct),
+			WithBorrowValidator(fixture.validate))
+	if err != nil {
+		t.Fatalf("New() create pool failed, err:%v", err)
+	}
+	defer pool.Close()
+
+	first := get_object_and_check(t, pool)
+	second := get_object_and_check(t, pool)
+	pool.ReturnObject(first)
+	pool.ReturnObject(second)
+	fixture.set_broken(second.ExtractObject())
+
+	// second is the most recently returned, it must be replaced by first.
+	object_holder := get_object_and_check(t, pool)
+	if object_holder != first {
+		t.Fatalf("broken idle object should be skipped, expect:%p, get:%p", first, object_holder)
+	}
+	if fixture.destructed_count() != 1 || pool.GetObjectCount() != 1 {
+		t.Fatalf("broken idle object should be destructed, destructed:%d, object_count:%d",
+			fixture.destructed_count(), pool.GetObjectCount())
+	}
+
+	fixture.set_broken(first.ExtractObject())
+	pool.ReturnObject(object_holder)
+	object_holder = get_object_and_check(t, pool)
+	if object_holder == first || object_holder.GetUseCount() != 1 {
+		t.Fatalf("broken idle object should be replaced by a new one, get:%p, use_count:%d",
+			object_holder, object_holder.GetUseCount())
+	}
+}
+
+func TestValidate_Return(t *testing.T) {
+	fixture := new_validator_fixture()
+	pool, err := New(test_object_constructor,
+			WithDestructor(fixture.destruct),
+			WithReturnValidator(fixture.validate))
+	if err != nil {
+		t.Fatalf("New() create pool failed, err:%v", err)
+	}
+	defer pool.Close()
+
+	object_holder := get_object_and_check(t, pool)
+	fixture.set_broken(object_holder.ExtractObject())
+
+	err = pool.ReturnObject(object_holder)
+	if err != nil {
+		t.Fatalf("Return Object failed, err:%v", err)
+	}
+	if pool.GetObjectCount() != 0 || fixture.destructed_count() != 1 {
+		t.Fatalf("broken object should be destructed on return, object_count:%d, destructed:%d",
+			pool.GetObjectCount(), fixture.destructed_count())
+	}
+
+	if err := pool.ReturnObject(object_holder); err != ErrNotExists {
+		t.Fatalf("returning twice should fail with ErrNotExists, err:%v", err)
+	}
+}
+
+
 func TestItemIdle(t *testing.T) {
 	pool, err := NewObjectPool(uint_512, uint_1024, idle_300s,
 					conn_constructor, conn_destructor, conn_id_extractor)
@@ -551,8 +967,8 @@ func TestGetObjectCount(t *testing.T) {
 
 	object_active_count := 10
 	object_idle_count := 10
-	object_active := make([]*objectHolder, 10)
-    object_idle := make([]*objectHolder, 10)
+	object_active := make([]*ObjectHolder, 10)
+    object_idle := make([]*ObjectHolder, 10)
 	for idx := 0; idx < object_active_count; idx += 1 {
 		object_active[idx] = get_object_and_check(t, pool)
 	}
@@ -624,11 +1040,11 @@ func fatal_is_not_nil(t *testing.T, object interface{}) {
 
 func fatal_error(t *testing.T, err error) {
 	if err != nil {
-		t.Fatal("err:%s", err)
+		t.Fatalf("err:%s", err)
 	}
 }
 
-func new_pool(t *testing.T) *objectPool{
+func new_pool(t *testing.T) *BasicPool{
 	pool, err := NewObjectPool(uint_512, uint_1024, idle_300s,
 					conn_constructor, conn_destructor, conn_id_extractor)
 	if err != nil {
@@ -638,10 +1054,200 @@ func new_pool(t *testing.T) *objectPool{
 	return pool
 }
 
-func get_object_and_check(t *testing.T, pool *objectPool) *objectHolder {
+func get_object_and_check(t *testing.T, pool *BasicPool) *ObjectHolder {
 	object_holder, err := pool.GetObject()
 	if err != nil {
 		t.Fatalf("GetObject() failed")
 	}
 	return object_holder
 }
+
+// bench_pool lets the benchmarks below compare BasicPool with sync.Pool and
+// a buffered channel.
+type bench_pool interface {
+	get() (interface{}, error)
+	put(interface{})
+}
+
+type bench_object_pool struct {
+	pool *BasicPool
+}
+
+func (p bench_object_pool) get() (interface{}, error) {
+	return p.pool.GetObject()
+}
+
+func (p bench_object_pool) put(object interface{}) {
+	p.pool.ReturnObject(object.(*ObjectHolder))
+}
+
+type bench_sync_pool struct {
+	pool *sync.Pool
+}
+
+func (p bench_sync_pool) get() (interface{}, error) {
+	return p.pool.Get(), nil
+}
+
+func (p bench_sync_pool) put(object interface{}) {
+	p.pool.Put(object)
+}
+
+// bench_channel_pool creates an object when the channel is empty and drops
+// it when the channel is full.
+type bench_channel_pool struct {
+	objects chan interface{}
+}
+
+func (p bench_channel_pool) get() (interface{}, error) {
+	select {
+	case object := <-p.objects:
+		return object, nil
+	default:
+		return test_object_constructor()
+	}
+}
+
+func (p bench_channel_pool) put(object interface{}) {
+	select {
+	case p.objects <- object:
+	default:
+	}
+}
+
+func new_bench_pools(b *testing.B, max_object uint32, opts ...Option) map[string]func() bench_pool {
+	return map[string]func() bench_pool{
+		"BasicPool": func() bench_pool {
+			pool, err := New(test_object_constructor, append([]Option{WithMaxObjects(max_object)}, opts...)...)
+			if err != nil {
+				b.Fatalf("New() create pool failed, err:%v", err)
+			}
+			return bench_object_pool{pool: pool}
+		},
+		"syncPool": func() bench_pool {
+			return bench_sync_pool{pool: &sync.Pool{New: func() interface{} {
+				object, _ := test_object_constructor()
+				return object
+			}}}
+		},
+		"channelPool": func() bench_pool {
+			return bench_channel_pool{objects: make(chan interface{}, max_object)}
+		},
+	}
+}
+
+func close_bench_pool(pool bench_pool) {
+	if pool, ok := pool.(bench_object_pool); ok {
+		pool.pool.Close()
+	}
+}
+
+func run_bench_pools(b *testing.B, max_object uint32, bench func(*testing.B, bench_pool)) {
+	pools := new_bench_pools(b, max_object)
+	for _, name := range []string{"BasicPool", "syncPool", "channelPool"} {
+		b.Run(name, func(b *testing.B) {
+			pool := pools[name]()
+			defer close_bench_pool(pool)
+			b.ReportAllocs()
+			b.ResetTimer()
+			bench(b, pool)
+		})
+	}
+}
+
+func BenchmarkBorrow_Uncontended(b *testing.B) {
+	run_bench_pools(b, 64, func(b *testing.B, pool bench_pool) {
+		for i := 0; i < b.N; i += 1 {
+			object, err := pool.get()
+			if err != nil {
+				b.Fatalf("get() failed, err:%s", err)
+			}
+			pool.put(object)
+		}
+	})
+}
+
+// every goroutine borrows one object at a time, GOMAXPROCS of them by
+// default, run with -cpu to vary the contention.
+func BenchmarkBorrow_Contended(b *testing.B) {
+	run_bench_pools(b, 1024, func(b *testing.B, pool bench_pool) {
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				object, err := pool.get()
+				if err != nil {
+					b.Errorf("get() failed, err:%s", err)
+					return
+				}
+				pool.put(object)
+			}
+		})
+	})
+}
+
+// every object is used once, so each borrow constructs and each return
+// destructs.
+func BenchmarkBorrow_Create(b *testing.B) {
+	b.Run("BasicPool", func(b *testing.B) {
+		pool, err := New(test_object_constructor, WithMaxUseCount(1))
+		if err != nil {
+			b.Fatalf("New() create pool failed, err:%v", err)
+		}
+		defer pool.Close()
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i += 1 {
+			object, err := pool.GetObject()
+			if err != nil {
+				b.Fatalf("GetObject() failed, err:%s", err)
+			}
+			pool.ReturnObject(object)
+		}
+	})
+	b.Run("syncPool", func(b *testing.B) {
+		pool := &sync.Pool{New: func() interface{} {
+			object, _ := test_object_constructor()
+			return object
+		}}
+		b.ReportAllocs()
+		for i := 0; i < b.N; i += 1 {
+			// never put back, so Get() always constructs.
+			pool.Get()
+		}
+	})
+	b.Run("channelPool", func(b *testing.B) {
+		pool := bench_channel_pool{objects: make(chan interface{})}
+		b.ReportAllocs()
+		for i := 0; i < b.N; i += 1 {
+			object, _ := pool.get()
+			// unbuffered, the object is dropped.
+			pool.put(object)
+		}
+	})
+}
+
+// objects are evicted as soon as they are idle, so the pool keeps creating
+// and destructing a batch of objects. sync.Pool and a channel have no
+// eviction to compare with.
+func BenchmarkBorrow_EvictionChurn(b *testing.B) {
+	pool, err := New(test_object_constructor, WithIdleTimeout(0), WithEvictionInterval(0))
+	if err != nil {
+		b.Fatalf("New() create pool failed, err:%v", err)
+	}
+	defer pool.Close()
+
+	objects := make([]*ObjectHolder, 8)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i += 1 {
+		for idx := range objects {
+			objects[idx], err = pool.GetObject()
+			if err != nil {
+				b.Fatalf("GetObject() failed, err:%s", err)
+			}
+		}
+		for _, object := range objects {
+			pool.ReturnObject(object)
+		}
+		pool.EvictIdleObjects()
+	}
+}
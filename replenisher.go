@@ -0,0 +1,136 @@
+package ObjectPool
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// how long the replenisher backs off after a failed construction.
+const replenishRetryDelay = time.Second
+
+// fillMinObjects creates idle objects until the pool holds minObjectCount,
+// it returns the number of failed constructions and the last error.
+func (p *BasicPool) fillMinObjects() (int, error) {
+	p.mutex.Lock()
+	need := int(p.minObjectCount) - len(p.idlePool) - len(p.activePool)
+	p.mutex.Unlock()
+	if need <= 0 {
+		return 0, nil
+	}
+
+	parallelism := p.config.PrefillParallelism
+	if parallelism < 1 {
+		parallelism = 1
+	}
+	if parallelism > need {
+		parallelism = need
+	}
+
+	jobs := make(chan struct{}, need)
+	for idx := 0; idx < need; idx += 1 {
+		jobs <- struct{}{}
+	}
+	close(jobs)
+
+	var (
+		wg       sync.WaitGroup
+		mutex    sync.Mutex
+		failures int
+		lastErr  error
+	)
+	for idx := 0; idx < parallelism; idx += 1 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range jobs {
+				if err := p.createIdleObject(); err != nil {
+					mutex.Lock()
+					failures += 1
+					lastErr = err
+					mutex.Unlock()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	return failures, lastErr
+}
+
+// createIdleObject adds one new object to idlePool, or hands it to a waiter.
+func (p *BasicPool) createIdleObject() error {
+	p.mutex.Lock()
+	if p.closed {
+		p.mutex.Unlock()
+		return ErrIsClosed
+	}
+	object := p.tryReserveLocked()
+	if object == nil {
+		p.mutex.Unlock()
+		return nil
+	}
+	object.useCount.Store(0)
+	p.mutex.Unlock()
+
+	object, err := p.constructObject(context.Background(), object)
+	if err != nil {
+		return err
+	}
+
+	p.mutex.Lock()
+	if p.closed {
+		p.returnClosedLocked(object)
+		return ErrIsClosed
+	}
+	delete(p.activePool, object)
+	p.putIdleLocked(object)
+	p.mutex.Unlock()
+	return nil
+}
+
+// signalReplenishLocked wakes the replenisher if the pool dropped below
+// minObjectCount.
+func (p *BasicPool) signalReplenishLocked() {
+	if p.replenishSignal == nil || p.closed {
+		return
+	}
+	if len(p.idlePool)+len(p.activePool) >= int(p.minObjectCount) {
+		return
+	}
+	select {
+	case p.replenishSignal <- struct{}{}:
+	default:
+	}
+}
+
+func (p *BasicPool) replenisher() {
+	defer p.background.Done()
+
+	for {
+		select {
+		case <-p.replenishSignal:
+		case <-p.stopSignal:
+			return
+		}
+
+		failures, _ := p.fillMinObjects()
+		if failures == 0 {
+			continue
+		}
+
+		// failed constructions signal again, do not spin on them.
+		select {
+		case <-time.After(replenishRetryDelay):
+		case <-p.stopSignal:
+			return
+		}
+		select {
+		case <-p.replenishSignal:
+		default:
+		}
+		p.mutex.Lock()
+		p.signalReplenishLocked()
+		p.mutex.Unlock()
+	}
+}
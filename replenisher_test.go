@@ -0,0 +1,92 @@
+package ObjectPool
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fails the first fail_count constructions.
+func failing_constructor(fail_count int32) (Constructor, *int32) {
+	calls := int32(0)
+	return func() (interface{}, error) {
+		if atomic.AddInt32(&calls, 1) <= fail_count {
+			return nil, errors.New("constructor failed")
+		}
+		return test_object_constructor()
+	}, &calls
+}
+
+func TestPrefill_OK(t *testing.T) {
+	constructor, calls := failing_constructor(0)
+	pool, err := New(constructor,
+			WithMinObjects(5),
+			WithMaxObjects(10),
+			WithPrefill(3, 0))
+	if err != nil {
+		t.Fatalf("New() create pool failed, err:%v", err)
+	}
+	defer pool.Close()
+
+	if pool.GetIdleObjectCount() != 5 || atomic.LoadInt32(calls) != 5 {
+		t.Fatalf("pool should be prefilled, idle:%d, constructor_calls:%d",
+			pool.GetIdleObjectCount(), atomic.LoadInt32(calls))
+	}
+
+	object_holder := get_object_and_check(t, pool)
+	if object_holder.GetUseCount() != 1 {
+		t.Fatalf("Invalid UseCount, expect:%d, get:%d", 1, object_holder.GetUseCount())
+	}
+}
+
+func TestPrefill_FailureTolerance(t *testing.T) {
+	constructor, _ := failing_constructor(2)
+	_, err := New(constructor,
+			WithMinObjects(5),
+			WithPrefill(2, 1))
+	if err == nil {
+		t.Fatalf("New() should fail when prefill failures exceed tolerance")
+	}
+
+	constructor, _ = failing_constructor(2)
+	pool, err := New(constructor,
+			WithMinObjects(5),
+			WithPrefill(2, 2))
+	if err != nil {
+		t.Fatalf("New() should tolerate prefill failures, err:%v", err)
+	}
+	defer pool.Close()
+
+	if pool.GetIdleObjectCount() != 3 {
+		t.Fatalf("idle object count invalid, expect:%d, get:%d", 3, pool.GetIdleObjectCount())
+	}
+}
+
+func TestReplenish_OK(t *testing.T) {
+	pool, err := New(test_object_constructor,
+			WithMinObjects(3),
+			WithPrefill(1, 0),
+			WithReplenish(true))
+	if err != nil {
+		t.Fatalf("New() create pool failed, err:%v", err)
+	}
+	defer pool.Close()
+
+	object_holder := get_object_and_check(t, pool)
+	object_holder.MarkUnusable()
+	pool.ReturnObject(object_holder)
+
+	deadline := time.Now().Add(idle_2s)
+	for {
+		report := pool.EvictIdleObjects()
+		if report.ObjectCount == 3 && report.IdleObjectCount == 3 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("pool should be replenished to min objects, idle:%d, total:%d",
+				report.IdleObjectCount, report.ObjectCount)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,108 @@
+package ObjectPool
+
+import "time"
+
+// SetMaxObjectCount changes the max object count at runtime. Shrinking
+// destructs surplus idle objects at once, surplus borrowed objects are
+// destructed when returned. Growing hands the new capacity to waiters.
+func (p *BasicPool) SetMaxObjectCount(max_object uint32) error {
+	p.mutex.Lock()
+	if p.closed {
+		p.mutex.Unlock()
+		return ErrIsClosed
+	}
+
+	config := p.config
+	config.MaxObjectCount = max_object
+	if err := config.Validate(); err != nil {
+		p.mutex.Unlock()
+		return err
+	}
+	// only the changed field, others are read without lock.
+	p.config.MaxObjectCount = max_object
+	p.maxObjectCount = max_object
+	p.resizeDestructQueueLocked()
+	// keeps returns off idleStack until the surplus is known.
+	p.overMax.Store(true)
+	p.flushParkedLocked()
+
+	var surplus []*ObjectHolder
+	if max_object != UnlimitedObjectCount {
+		count := len(p.idlePool) + len(p.activePool) - int(max_object)
+		if count > len(p.idlePool) {
+			count = len(p.idlePool)
+		}
+		if count > 0 {
+			// least recently used first.
+			surplus = make([]*ObjectHolder, count)
+			copy(surplus, p.idlePool[:count])
+			copy(p.idlePool, p.idlePool[count:])
+			p.idlePool = p.idlePool[:len(p.idlePool)-count]
+			p.destroyedCount += uint64(count)
+		}
+	}
+	p.releaseSlotLocked()
+	p.updateOverMaxLocked()
+	p.mutex.Unlock()
+
+	for _, object := range surplus {
+		p.evictObject(object, evictResize)
+	}
+	return nil
+}
+
+// SetMinObjectCount changes the min object count at runtime, the pool grows
+// to it in background with Replenish enabled. Shrinking leaves surplus
+// objects to the idle eviction.
+func (p *BasicPool) SetMinObjectCount(min_object uint32) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.closed {
+		return ErrIsClosed
+	}
+
+	config := p.config
+	config.MinObjectCount = min_object
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	p.config.MinObjectCount = min_object
+	p.minObjectCount = min_object
+	p.resizeDestructQueueLocked()
+	p.signalReplenishLocked()
+	return nil
+}
+
+// SetIdleTime changes how long objects may stay idle, it applies to objects
+// already idle on the next eviction.
+func (p *BasicPool) SetIdleTime(idle_time time.Duration) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.closed {
+		return ErrIsClosed
+	}
+
+	config := p.config
+	config.IdleTime = idle_time
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	p.config.IdleTime = idle_time
+	p.idleTime = idle_time
+	return nil
+}
+
+// resizeDestructQueueLocked recomputes decreaseStep, the old queue is
+// drained by its own destructor.
+func (p *BasicPool) resizeDestructQueueLocked() {
+	p.decreaseStep = p.config.decreaseStep()
+	if cap(p.destructQueue) == int(p.decreaseStep)*2 {
+		return
+	}
+	close(p.destructQueue)
+	p.destructQueue = make(chan *ObjectHolder, p.decreaseStep*2)
+	p.destructors.Add(1)
+	go p.idleObjectDestructor(p.destructQueue)
+}
@@ -0,0 +1,128 @@
+package ObjectPool
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSetMaxObjectCount_Shrink(t *testing.T) {
+	fixture := new_validator_fixture()
+	pool, err := New(test_object_constructor, WithMaxObjects(4), WithDestructor(fixture.destruct))
+	if err != nil {
+		t.Fatalf("New() create pool failed, err:%v", err)
+	}
+	defer pool.Close()
+
+	return_in_order(t, pool, 2)
+	first := get_object_and_check(t, pool)
+	second := get_object_and_check(t, pool)
+	if pool.GetIdleObjectCount() != 0 {
+		t.Fatalf("idle object count mismatch, expect:%d, get:%d", 0, pool.GetIdleObjectCount())
+	}
+	get_object_and_check(t, pool)
+	get_object_and_check(t, pool)
+	pool.ReturnObject(first)
+	pool.ReturnObject(second)
+
+	// 2 idle and 2 borrowed.
+	if err := pool.SetMaxObjectCount(1); err != nil {
+		t.Fatalf("SetMaxObjectCount() failed, err:%s", err)
+	}
+	if fixture.destructed_count() != 2 || pool.GetObjectCount() != 2 {
+		t.Fatalf("surplus idle objects should be destructed, destructed:%d, count:%d",
+			fixture.destructed_count(), pool.GetObjectCount())
+	}
+	if pool.GetMaxObjectCount() != 1 || pool.Config().MaxObjectCount != 1 {
+		t.Fatalf("max object count mismatch, expect:%d, get:%d", 1, pool.GetMaxObjectCount())
+	}
+	if err := pool.SetMinObjectCount(2); err == nil {
+		t.Fatalf("min object count above max should fail")
+	}
+}
+
+func TestSetMaxObjectCount_DrainOnReturn(t *testing.T) {
+	pool := new_test_object_pool(t, 0, 2)
+	defer pool.Close()
+
+	first := get_object_and_check(t, pool)
+	second := get_object_and_check(t, pool)
+	if err := pool.SetMaxObjectCount(1); err != nil {
+		t.Fatalf("SetMaxObjectCount() failed, err:%s", err)
+	}
+
+	pool.ReturnObject(first)
+	if pool.GetObjectCount() != 1 || pool.GetIdleObjectCount() != 0 {
+		t.Fatalf("surplus borrowed object should be destructed on return, count:%d", pool.GetObjectCount())
+	}
+	pool.ReturnObject(second)
+	if pool.GetIdleObjectCount() != 1 {
+		t.Fatalf("object within max should be kept, idle:%d", pool.GetIdleObjectCount())
+	}
+}
+
+func TestSetMaxObjectCount_GrowWakesWaiter(t *testing.T) {
+	pool := new_test_object_pool(t, 0, 1)
+	defer pool.Close()
+
+	get_object_and_check(t, pool)
+	go func() {
+		time.Sleep(idle_50ms)
+		pool.SetMaxObjectCount(2)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), idle_2s)
+	defer cancel()
+	if _, err := pool.GetObjectContext(ctx); err != nil {
+		t.Fatalf("growing max should wake waiter, err:%v", err)
+	}
+}
+
+func TestSetMinObjectCount_Replenish(t *testing.T) {
+	pool, err := New(test_object_constructor, WithReplenish(true))
+	if err != nil {
+		t.Fatalf("New() create pool failed, err:%v", err)
+	}
+	defer pool.Close()
+
+	step := pool.decreaseStep
+	if err := pool.SetMinObjectCount(3); err != nil {
+		t.Fatalf("SetMinObjectCount() failed, err:%s", err)
+	}
+	deadline := time.Now().Add(idle_2s)
+	for pool.GetIdleObjectCount() != 3 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if pool.GetIdleObjectCount() != 3 {
+		t.Fatalf("pool should grow to min, expect:%d, get:%d", 3, pool.GetIdleObjectCount())
+	}
+
+	if err := pool.SetMaxObjectCount(1000); err != nil {
+		t.Fatalf("SetMaxObjectCount() failed, err:%s", err)
+	}
+	if pool.decreaseStep == step || cap(pool.destructQueue) != int(pool.decreaseStep) * 2 {
+		t.Fatalf("decrease step should be recomputed, step:%d, queue:%d", pool.decreaseStep, cap(pool.destructQueue))
+	}
+}
+
+func TestSetIdleTime(t *testing.T) {
+	pool := new_test_object_pool(t, 0, 2)
+	defer pool.Close()
+
+	return_in_order(t, pool, 1)
+	if err := pool.SetIdleTime(-time.Second); err == nil {
+		t.Fatalf("negative idle time should fail")
+	}
+	if err := pool.SetIdleTime(0); err != nil {
+		t.Fatalf("SetIdleTime() failed, err:%s", err)
+	}
+	report := pool.EvictIdleObjects()
+	if len(report.EvictedIds) != 1 || pool.GetIdleTime() != 0 {
+		t.Fatalf("idle object should be evicted with new idle time, report:%+v", report)
+	}
+
+	pool.Close()
+	if err := pool.SetIdleTime(time.Second); err != ErrIsClosed {
+		t.Fatalf("closed pool should not be resized, err:%v", err)
+	}
+}
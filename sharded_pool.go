@@ -0,0 +1,241 @@
+package ObjectPool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var _ ObjectPool = (*ShardedPool)(nil)
+
+// ShardedPool splits objects across shards, each with its own mutex, to
+// reduce contention under many concurrent borrowers. A borrower starts at a
+// random shard and steals idle objects from the others before creating a
+// new one, MaxObjectCount caps all shards together. Objects go back to the
+// shard that created them.
+type ShardedPool struct {
+	shards  []*BasicPool
+	limiter *capacityLimiter
+	config  Config
+	closed  atomic.Bool
+
+	// borrowers wait on limiter rather than on a shard, Stats() adds these
+	// to the shards' counters.
+	waiters      atomic.Int32
+	waitCount    atomic.Uint64
+	waitDuration atomic.Int64
+}
+
+// NewShardedPool creates a pool with shard_count shards, GOMAXPROCS if it is
+// not positive. MinObjectCount is split evenly across shards.
+func NewShardedPool(constructor Constructor, shard_count int, opts ...Option) (*ShardedPool, error) {
+	if constructor == nil {
+		return nil, errors.New("need parameter constructor")
+	}
+
+	config := DefaultConfig()
+	for _, opt := range opts {
+		opt(&config)
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	if shard_count <= 0 {
+		shard_count = runtime.GOMAXPROCS(0)
+	}
+
+	pool := &ShardedPool{
+		shards:  make([]*BasicPool, shard_count),
+		limiter: newCapacityLimiter(config.MaxObjectCount),
+		config:  config,
+	}
+	for idx := range pool.shards {
+		shard_config := config
+		shard_config.MaxObjectCount = UnlimitedObjectCount
+		shard_config.MinObjectCount = config.MinObjectCount / uint32(shard_count)
+		if uint32(idx) < config.MinObjectCount%uint32(shard_count) {
+			shard_config.MinObjectCount += 1
+		}
+
+		shard, err := newObjectPool(contextConstructor(constructor, shard_config), shard_config, pool.limiter)
+		if err != nil {
+			for _, created := range pool.shards[:idx] {
+				created.Close()
+			}
+			return nil, fmt.Errorf("create shard failed, shard:%d, err:%s", idx, err)
+		}
+		pool.shards[idx] = shard
+	}
+	return pool, nil
+}
+
+func (s *ShardedPool) GetObject() (*ObjectHolder, error) {
+	return s.getObject(context.Background(), borrowNoWait)
+}
+
+func (s *ShardedPool) GetObjectContext(ctx context.Context) (*ObjectHolder, error) {
+	return s.getObject(ctx, borrowWait)
+}
+
+func (s *ShardedPool) getObject(ctx context.Context, mode borrowMode) (*ObjectHolder, error) {
+	home := rand.Intn(len(s.shards))
+	object, err := s.tryGetObject(ctx, home)
+	if err != ErrReachMaxLimit || mode != borrowWait {
+		return object, err
+	}
+
+	s.waitCount.Add(1)
+	s.waiters.Add(1)
+	wait_start := time.Now()
+	defer func() {
+		s.waiters.Add(-1)
+		s.waitDuration.Add(int64(time.Since(wait_start)))
+	}()
+	for {
+		changed := s.limiter.subscribe()
+		object, err := s.tryGetObject(ctx, home)
+		if err != ErrReachMaxLimit {
+			s.limiter.unsubscribe()
+			return object, err
+		}
+		select {
+		case <-changed:
+			s.limiter.unsubscribe()
+		case <-ctx.Done():
+			s.limiter.unsubscribe()
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// tryGetObject takes an idle object of the home shard, steals one from the
+// other shards, or creates one in the home shard, in that order.
+func (s *ShardedPool) tryGetObject(ctx context.Context, home int) (*ObjectHolder, error) {
+	if s.closed.Load() {
+		return nil, ErrIsClosed
+	}
+	for idx := 0; idx < len(s.shards); idx += 1 {
+		shard := s.shards[(home+idx)%len(s.shards)]
+		object, err := shard.getObject(ctx, borrowIdleOnly, 0)
+		if err != ErrReachMaxLimit {
+			return object, err
+		}
+	}
+	return s.shards[home].getObject(ctx, borrowNoWait, 0)
+}
+
+func (s *ShardedPool) ReturnObject(object *ObjectHolder) error {
+	if object == nil {
+		return nil
+	}
+	for _, shard := range s.shards {
+		if shard == object.owner {
+			return shard.ReturnObject(object)
+		}
+	}
+	return ErrNotExists
+}
+
+// Shutdown shuts all shards down concurrently, see BasicPool.Shutdown().
+func (s *ShardedPool) Shutdown(ctx context.Context) error {
+	s.closed.Store(true)
+	// waiters see ErrIsClosed.
+	s.limiter.broadcast()
+
+	errs := make([]error, len(s.shards))
+	var wg sync.WaitGroup
+	for idx, shard := range s.shards {
+		wg.Add(1)
+		go func(idx int, shard *BasicPool) {
+			defer wg.Done()
+			errs[idx] = shard.Shutdown(ctx)
+		}(idx, shard)
+	}
+	wg.Wait()
+
+	var forced []string
+	for _, err := range errs {
+		var shutdownErr *ShutdownError
+		if errors.As(err, &shutdownErr) {
+			forced = append(forced, shutdownErr.ForceClosedIds...)
+		}
+	}
+	if len(forced) > 0 {
+		return &ShutdownError{ForceClosedIds: forced, Err: ctx.Err()}
+	}
+	return nil
+}
+
+func (s *ShardedPool) Close() {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	s.Shutdown(ctx)
+}
+
+func (s *ShardedPool) IsClosed() bool {
+	return s.closed.Load()
+}
+
+func (s *ShardedPool) GetObjectCount() uint32 {
+	return uint32(s.limiter.count.Load())
+}
+
+func (s *ShardedPool) GetIdleObjectCount() uint32 {
+	count := uint32(0)
+	for _, shard := range s.shards {
+		count += shard.GetIdleObjectCount()
+	}
+	return count
+}
+
+func (s *ShardedPool) GetMaxObjectCount() uint32 {
+	return s.config.MaxObjectCount
+}
+
+func (s *ShardedPool) GetMinObjectCount() uint32 {
+	return s.config.MinObjectCount
+}
+
+func (s *ShardedPool) GetIdleTime() time.Duration {
+	return s.config.IdleTime
+}
+
+func (s *ShardedPool) ShardCount() int {
+	return len(s.shards)
+}
+
+// Stats adds up the snapshots of all shards, they are not taken at the
+// same instant.
+func (s *ShardedPool) Stats() Stats {
+	var total Stats
+	for idx, shard := range s.shards {
+		stats := shard.Stats()
+		if idx == 0 {
+			total = stats
+			continue
+		}
+		total.IdleObjectCount += stats.IdleObjectCount
+		total.ActiveObjectCount += stats.ActiveObjectCount
+		total.ObjectCount += stats.ObjectCount
+		total.WaiterCount += stats.WaiterCount
+		total.CreatedCount += stats.CreatedCount
+		total.DestroyedCount += stats.DestroyedCount
+		total.ConstructFailureCount += stats.ConstructFailureCount
+		total.EvictedCount += stats.EvictedCount
+		total.WaitCount += stats.WaitCount
+		total.WaitDuration += stats.WaitDuration
+		total.BorrowWaitHistogram = total.BorrowWaitHistogram.merge(stats.BorrowWaitHistogram)
+		total.HoldHistogram = total.HoldHistogram.merge(stats.HoldHistogram)
+	}
+	total.WaiterCount += uint32(s.waiters.Load())
+	total.WaitCount += s.waitCount.Load()
+	total.WaitDuration += time.Duration(s.waitDuration.Load())
+	total.MaxObjectCount = s.config.MaxObjectCount
+	total.MinObjectCount = s.config.MinObjectCount
+	return total
+}
@@ -0,0 +1,182 @@
+package ObjectPool
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func new_test_sharded_pool(t *testing.T, shard_count int, opts ...Option) *ShardedPool {
+	pool, err := NewShardedPool(test_object_constructor, shard_count, opts...)
+	if err != nil {
+		t.Fatalf("NewShardedPool() create pool failed, err:%v", err)
+	}
+	return pool
+}
+
+func TestShardedPool_GlobalMax(t *testing.T) {
+	pool := new_test_sharded_pool(t, 4, WithMaxObjects(3))
+	defer pool.Close()
+
+	var objects []*ObjectHolder
+	for i := 0; i < 3; i += 1 {
+		object, err := pool.GetObject()
+		if err != nil {
+			t.Fatalf("GetObject() failed, err:%s", err)
+		}
+		objects = append(objects, object)
+	}
+	if _, err := pool.GetObject(); err != ErrReachMaxLimit {
+		t.Fatalf("global max should be reached, err:%v", err)
+	}
+
+	for _, object := range objects {
+		if err := pool.ReturnObject(object); err != nil {
+			t.Fatalf("ReturnObject() failed, err:%s", err)
+		}
+	}
+	if err := pool.ReturnObject(NewObjectHolder(&test_object{})); err != ErrNotExists {
+		t.Fatalf("foreign object should not be returned, err:%v", err)
+	}
+	stats := pool.Stats()
+	if stats.ObjectCount != 3 || stats.IdleObjectCount != 3 || stats.MaxObjectCount != 3 {
+		t.Fatalf("stats mismatch, stats:%+v", stats)
+	}
+}
+
+func TestShardedPool_Steal(t *testing.T) {
+	pool := new_test_sharded_pool(t, 8, WithMinObjects(1), WithPrefill(1, 0))
+	defer pool.Close()
+
+	if pool.GetObjectCount() != 1 {
+		t.Fatalf("object count mismatch, expect:%d, get:%d", 1, pool.GetObjectCount())
+	}
+	// whatever shard a borrower starts at, the only idle object is stolen.
+	for i := 0; i < 20; i += 1 {
+		object, err := pool.GetObject()
+		if err != nil {
+			t.Fatalf("GetObject() failed, err:%s", err)
+		}
+		pool.ReturnObject(object)
+	}
+	if stats := pool.Stats(); stats.CreatedCount != 1 {
+		t.Fatalf("idle object should be stolen instead of creating, created:%d", stats.CreatedCount)
+	}
+}
+
+func TestShardedPool_Wait(t *testing.T) {
+	pool := new_test_sharded_pool(t, 4, WithMaxObjects(1))
+
+	object, _ := pool.GetObject()
+	go func() {
+		time.Sleep(idle_50ms)
+		pool.ReturnObject(object)
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), idle_2s)
+	defer cancel()
+	borrowed, err := pool.GetObjectContext(ctx)
+	if err != nil || borrowed != object {
+		t.Fatalf("waiter should get the returned object, err:%v", err)
+	}
+
+	result := make(chan error, 1)
+	go func() {
+		_, err := pool.GetObjectContext(context.Background())
+		result <- err
+	}()
+	time.Sleep(idle_50ms)
+	pool.Close()
+	if err := <-result; err != ErrIsClosed {
+		t.Fatalf("waiter should fail on shutdown, err:%v", err)
+	}
+}
+
+func TestShardedPool_WaitStats(t *testing.T) {
+	pool := new_test_sharded_pool(t, 4, WithMaxObjects(1))
+	defer pool.Close()
+
+	object, _ := pool.GetObject()
+	result := make(chan error, 1)
+	go func() {
+		borrowed, err := pool.GetObjectContext(context.Background())
+		if err == nil {
+			pool.ReturnObject(borrowed)
+		}
+		result <- err
+	}()
+	deadline := time.Now().Add(idle_2s)
+	for pool.Stats().WaiterCount != 1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("waiter should be counted, stats:%+v", pool.Stats())
+		}
+		time.Sleep(time.Millisecond)
+	}
+	pool.ReturnObject(object)
+	if err := <-result; err != nil {
+		t.Fatalf("waiter should get the returned object, err:%v", err)
+	}
+
+	stats := pool.Stats()
+	if stats.WaiterCount != 0 || stats.WaitCount != 1 || stats.WaitDuration <= 0 {
+		t.Fatalf("wait stats mismatch, stats:%+v", stats)
+	}
+}
+
+func TestShardedPool_Concurrency(t *testing.T) {
+	pool := new_test_sharded_pool(t, 0, WithMaxObjects(16))
+	defer pool.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 64; i += 1 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j += 1 {
+				object, err := pool.GetObjectContext(context.Background())
+				if err != nil {
+					t.Errorf("GetObjectContext() failed, err:%s", err)
+					return
+				}
+				pool.ReturnObject(object)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if pool.GetObjectCount() > 16 {
+		t.Fatalf("global max exceeded, count:%d", pool.GetObjectCount())
+	}
+}
+
+func benchmark_parallel_borrow(b *testing.B, pool ObjectPool) {
+	defer pool.Close()
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			object, err := pool.GetObjectContext(context.Background())
+			if err != nil {
+				b.Errorf("GetObjectContext() failed, err:%s", err)
+				return
+			}
+			pool.ReturnObject(object)
+		}
+	})
+}
+
+func BenchmarkObjectPool_ParallelBorrow(b *testing.B) {
+	pool, err := New(test_object_constructor, WithMaxObjects(64))
+	if err != nil {
+		b.Fatalf("New() create pool failed, err:%v", err)
+	}
+	benchmark_parallel_borrow(b, pool)
+}
+
+func BenchmarkShardedPool_ParallelBorrow(b *testing.B) {
+	pool, err := NewShardedPool(test_object_constructor, 0, WithMaxObjects(64))
+	if err != nil {
+		b.Fatalf("NewShardedPool() create pool failed, err:%v", err)
+	}
+	benchmark_parallel_borrow(b, pool)
+}
@@ -0,0 +1,153 @@
+package ObjectPool
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// ShutdownError lists borrowed objects Shutdown() had to destruct because
+// its context was done before they were returned.
+type ShutdownError struct {
+	ForceClosedIds []string
+	Err            error
+}
+
+func (e *ShutdownError) Error() string {
+	return fmt.Sprintf("object pool shutdown force closed %d borrowed objects, ids:[%s], error:%s",
+		len(e.ForceClosedIds), strings.Join(e.ForceClosedIds, ", "), e.Err)
+}
+
+func (e *ShutdownError) Unwrap() error {
+	return e.Err
+}
+
+// Shutdown stops lending objects and destructs idle ones at once, borrowed
+// objects are destructed as they are returned. If ctx is done first, the
+// objects still borrowed are destructed anyway and reported by a
+// *ShutdownError.
+func (p *BasicPool) Shutdown(ctx context.Context) error {
+	p.mutex.Lock()
+	var idle []*ObjectHolder
+	if !p.closed {
+		idle = p.closeLocked()
+	}
+	p.mutex.Unlock()
+
+	for _, object := range idle {
+		p.destroyObject(object)
+	}
+
+	var err error
+	select {
+	case <-p.drained:
+	case <-ctx.Done():
+		if forced := p.forceCloseActive(); len(forced) > 0 {
+			err = &ShutdownError{ForceClosedIds: forced, Err: ctx.Err()}
+			p.logger.Warn("borrowed objects force closed",
+				slog.Any("object_ids", forced),
+				slog.Any("error", ctx.Err()))
+		}
+	}
+
+	// must wait all object destructed.
+	p.destructors.Wait()
+	p.background.Wait()
+
+	return err
+}
+
+// closeLocked stops lending objects and returns the idle ones to be
+// destructed.
+func (p *BasicPool) closeLocked() []*ObjectHolder {
+	p.closed = true
+	p.closing.Store(true)
+	close(p.destructQueue)
+	close(p.stopSignal)
+
+	for _, waiter := range p.waitQueue.drain() {
+		close(waiter.ready)
+	}
+	p.waiters.Store(0)
+
+	idle := p.idlePool
+	p.idlePool = nil
+	p.destroyedCount += uint64(len(idle))
+	idle = append(idle, p.flushParkedLocked()...)
+	p.checkDrainedLocked()
+	return idle
+}
+
+// closeIfEmpty closes the pool if it holds no object and nobody waits for
+// one, Close() then only waits for background goroutines.
+func (p *BasicPool) closeIfEmpty() bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.closed || len(p.idlePool)+len(p.activePool) > 0 || p.waitQueue.Len() > 0 {
+		return false
+	}
+	p.closeLocked()
+	return true
+}
+
+// forceCloseActive destructs every borrowed object and returns their ids.
+func (p *BasicPool) forceCloseActive() []string {
+	p.mutex.Lock()
+	parked := p.flushParkedLocked()
+	var forced []*ObjectHolder
+	for object := range p.activePool {
+		// still being constructed, constructObject() cleans it up.
+		if object.object == nil {
+			delete(p.activePool, object)
+			continue
+		}
+		if !closeHolder(object) {
+			continue
+		}
+		delete(p.activePool, object)
+		forced = append(forced, object)
+	}
+	p.destroyedCount += uint64(len(forced))
+	p.checkDrainedLocked()
+	p.mutex.Unlock()
+
+	for _, object := range parked {
+		p.destroyObject(object)
+	}
+	ids := make([]string, len(forced))
+	for idx, object := range forced {
+		ids[idx] = object.id
+		p.destroyObject(object)
+	}
+	return ids
+}
+
+// closeHolder claims a borrowed object, or one a borrower is still
+// validating, so getObject() does not lend it once destructed.
+func closeHolder(object *ObjectHolder) bool {
+	for {
+		switch state := object.state.Load(); state {
+		case holderReturning, holderParked, holderClosed:
+			// its returner destructs it, the pool is closing.
+			return false
+		default:
+			if object.state.CompareAndSwap(state, holderClosed) {
+				return true
+			}
+		}
+	}
+}
+
+func (p *BasicPool) checkDrainedLocked() {
+	p.syncLimiterLocked()
+	if !p.closed || len(p.activePool) > 0 {
+		return
+	}
+	select {
+	case <-p.drained:
+	default:
+		close(p.drained)
+	}
+}
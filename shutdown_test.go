@@ -0,0 +1,130 @@
+package ObjectPool
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestShutdown_WaitBorrowed(t *testing.T) {
+	fixture := new_validator_fixture()
+	pool, err := New(test_object_constructor, WithDestructor(fixture.destruct))
+	if err != nil {
+		t.Fatalf("New() create pool failed, err:%v", err)
+	}
+
+	return_in_order(t, pool, 2)
+	borrowed := get_object_and_check(t, pool)
+
+	result := make(chan error, 1)
+	go func() {
+		result <- pool.Shutdown(context.Background())
+	}()
+	time.Sleep(idle_50ms)
+
+	// idle objects are destructed at once, borrowed one is still usable.
+	if fixture.destructed_count() != 1 {
+		t.Fatalf("idle objects should be destructed, destructed:%d", fixture.destructed_count())
+	}
+	if _, err := pool.GetObject(); err != ErrIsClosed {
+		t.Fatalf("shutting down pool should not lend, err:%v", err)
+	}
+	select {
+	case err := <-result:
+		t.Fatalf("Shutdown() should wait for borrowed objects, err:%v", err)
+	default:
+	}
+
+	if err := pool.ReturnObject(borrowed); err != nil {
+		t.Fatalf("return during shutdown failed, err:%s", err)
+	}
+	if err := <-result; err != nil {
+		t.Fatalf("Shutdown() failed, err:%s", err)
+	}
+	if fixture.destructed_count() != 2 || pool.Stats().DestroyedCount != 2 {
+		t.Fatalf("returned object should be destructed, destructed:%d", fixture.destructed_count())
+	}
+}
+
+func TestShutdown_ForceClose(t *testing.T) {
+	fixture := new_validator_fixture()
+	pool, err := New(test_object_constructor,
+			WithDestructor(fixture.destruct),
+			WithIdExtractor(test_object_id_extractor))
+	if err != nil {
+		t.Fatalf("New() create pool failed, err:%v", err)
+	}
+
+	returned := get_object_and_check(t, pool)
+	straggler := get_object_and_check(t, pool)
+	pool.ReturnObject(returned)
+
+	ctx, cancel := context.WithTimeout(context.Background(), idle_50ms)
+	defer cancel()
+	err = pool.Shutdown(ctx)
+
+	var shutdown_err *ShutdownError
+	if !errors.As(err, &shutdown_err) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Shutdown() should report force closed objects, err:%v", err)
+	}
+	straggler_id := test_object_id_extractor(straggler.ExtractObject())
+	if len(shutdown_err.ForceClosedIds) != 1 || shutdown_err.ForceClosedIds[0] != straggler_id {
+		t.Fatalf("force closed ids expect:[%s], get:%v", straggler_id, shutdown_err.ForceClosedIds)
+	}
+	if fixture.destructed_count() != 2 {
+		t.Fatalf("all objects should be destructed, destructed:%d", fixture.destructed_count())
+	}
+
+	// already destructed, must not be destructed twice.
+	if err := pool.ReturnObject(straggler); err != ErrIsClosed {
+		t.Fatalf("returning force closed object should fail with ErrIsClosed, err:%v", err)
+	}
+	if fixture.destructed_count() != 2 {
+		t.Fatalf("force closed object destructed twice")
+	}
+}
+
+func TestShutdown_Twice(t *testing.T) {
+	pool := new_test_object_pool(t, 0, 10)
+	return_in_order(t, pool, 2)
+
+	if err := pool.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() failed, err:%s", err)
+	}
+	if err := pool.Shutdown(context.Background()); err != nil {
+		t.Fatalf("second Shutdown() failed, err:%s", err)
+	}
+	pool.Close()
+	if !pool.IsClosed() || pool.GetObjectCount() != 0 {
+		t.Fatalf("pool should be closed and empty, object_count:%d", pool.GetObjectCount())
+	}
+}
+
+func TestShutdown_ForceCloseValidating(t *testing.T) {
+	validating := make(chan struct{})
+	release := make(chan struct{})
+	pool, err := New(test_object_constructor, WithBorrowValidator(func(interface{}) error {
+		close(validating)
+		<-release
+		return nil
+	}))
+	if err != nil {
+		t.Fatalf("New() create pool failed, err:%v", err)
+	}
+	return_in_order(t, pool, 1)
+
+	result := make(chan error, 1)
+	go func() {
+		_, err := pool.GetObject()
+		result <- err
+	}()
+	<-validating
+	// destructs the object the borrower is validating.
+	pool.Close()
+	close(release)
+
+	if err := <-result; err != ErrIsClosed {
+		t.Fatalf("force closed object should not be lent, err:%v", err)
+	}
+}
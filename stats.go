@@ -0,0 +1,142 @@
+package ObjectPool
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+var defaultHistogramBounds = []time.Duration{
+	100 * time.Microsecond,
+	500 * time.Microsecond,
+	time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	5 * time.Second,
+	10 * time.Second,
+	time.Minute,
+}
+
+// Stats is a consistent snapshot of an BasicPool, in the spirit of
+// database/sql.DBStats.
+type Stats struct {
+	MaxObjectCount uint32
+	MinObjectCount uint32
+
+	// objects still being constructed count as active.
+	IdleObjectCount   uint32
+	ActiveObjectCount uint32
+	ObjectCount       uint32
+	WaiterCount       uint32
+
+	// lifetime counters.
+	CreatedCount          uint64
+	DestroyedCount        uint64
+	ConstructFailureCount uint64
+	// idle objects destroyed by eviction, expiry or IdleValidator.
+	EvictedCount uint64
+	// GetObjectContext() calls that had to wait, and the total time waited.
+	WaitCount    uint64
+	WaitDuration time.Duration
+
+	// time spent in GetObject() by successful borrowers.
+	BorrowWaitHistogram DurationHistogram
+	// time objects were held before ReturnObject().
+	HoldHistogram DurationHistogram
+}
+
+// DurationHistogram is a snapshot of durations counted into buckets.
+type DurationHistogram struct {
+	// upper bounds, an implicit last bucket holds everything above.
+	Bounds []time.Duration
+	// Counts[i] is the number of durations <= Bounds[i], like the
+	// cumulative buckets of Prometheus.
+	Counts []uint64
+	Count  uint64
+	Sum    time.Duration
+}
+
+// durationHistogram is updated without holding the pool mutex.
+type durationHistogram struct {
+	bounds []time.Duration
+	counts []atomic.Uint64
+	count  atomic.Uint64
+	sum    atomic.Int64
+}
+
+func newDurationHistogram(bounds []time.Duration) *durationHistogram {
+	return &durationHistogram{
+		bounds: bounds,
+		counts: make([]atomic.Uint64, len(bounds)),
+	}
+}
+
+func (h *durationHistogram) observe(duration time.Duration) {
+	for idx, bound := range h.bounds {
+		if duration <= bound {
+			h.counts[idx].Add(1)
+			break
+		}
+	}
+	h.sum.Add(int64(duration))
+	h.count.Add(1)
+}
+
+func (h *durationHistogram) snapshot() DurationHistogram {
+	snapshot := DurationHistogram{
+		Bounds: h.bounds,
+		Counts: make([]uint64, len(h.bounds)),
+	}
+	cumulative := uint64(0)
+	for idx := range h.counts {
+		cumulative += h.counts[idx].Load()
+		snapshot.Counts[idx] = cumulative
+	}
+	snapshot.Sum = time.Duration(h.sum.Load())
+	snapshot.Count = h.count.Load()
+	// observations racing with the snapshot must not break monotonicity.
+	if snapshot.Count < cumulative {
+		snapshot.Count = cumulative
+	}
+	return snapshot
+}
+
+// merge adds up two snapshots with the same bounds.
+func (h DurationHistogram) merge(other DurationHistogram) DurationHistogram {
+	merged := DurationHistogram{
+		Bounds: h.Bounds,
+		Counts: make([]uint64, len(h.Counts)),
+		Count:  h.Count + other.Count,
+		Sum:    h.Sum + other.Sum,
+	}
+	for idx := range h.Counts {
+		merged.Counts[idx] = h.Counts[idx] + other.Counts[idx]
+	}
+	return merged
+}
+
+func (p *BasicPool) Stats() Stats {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	idle, active := p.countObjectsLocked()
+	return Stats{
+		MaxObjectCount:        p.maxObjectCount,
+		MinObjectCount:        p.minObjectCount,
+		IdleObjectCount:       idle,
+		ActiveObjectCount:     active,
+		ObjectCount:           uint32(len(p.idlePool) + len(p.activePool)),
+		WaiterCount:           uint32(p.waitQueue.Len()),
+		CreatedCount:          p.createdCount,
+		DestroyedCount:        p.destroyedCount,
+		ConstructFailureCount: p.constructFailureCount,
+		EvictedCount:          p.evictedCount,
+		WaitCount:             p.waitCount,
+		WaitDuration:          p.waitDuration,
+		BorrowWaitHistogram:   p.waitHistogram.snapshot(),
+		HoldHistogram:         p.holdHistogram.snapshot(),
+	}
+}
@@ -0,0 +1,120 @@
+package ObjectPool
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestStats_Counters(t *testing.T) {
+	constructor, _ := failing_constructor(1)
+	pool, err := New(constructor,
+			WithMinObjects(1),
+			WithMaxObjects(2),
+			WithEvictionInterval(0),
+			WithIdleTimeout(idle_50ms))
+	if err != nil {
+		t.Fatalf("New() create pool failed, err:%v", err)
+	}
+	defer pool.Close()
+
+	if _, err := pool.GetObject(); err == nil {
+		t.Fatalf("first construction should fail")
+	}
+	first := get_object_and_check(t, pool)
+	second := get_object_and_check(t, pool)
+
+	// wait for an object until it is returned.
+	go func() {
+		time.Sleep(idle_50ms)
+		pool.ReturnObject(second)
+	}()
+	waited, err := pool.GetObjectContext(context.Background())
+	if err != nil {
+		t.Fatalf("GetObjectContext() failed, err:%s", err)
+	}
+
+	stats := pool.Stats()
+	if stats.ObjectCount != 2 || stats.ActiveObjectCount != 2 || stats.IdleObjectCount != 0 {
+		t.Fatalf("invalid object counts, stats:%+v", stats)
+	}
+	if stats.WaitCount != 1 || stats.WaitDuration < idle_50ms / 2 || stats.WaiterCount != 0 {
+		t.Fatalf("invalid wait stats, stats:%+v", stats)
+	}
+	if stats.CreatedCount != 2 || stats.ConstructFailureCount != 1 || stats.DestroyedCount != 0 {
+		t.Fatalf("invalid lifetime counters, stats:%+v", stats)
+	}
+
+	first.MarkUnusable()
+	pool.ReturnObject(first)
+	pool.ReturnObject(waited)
+	time.Sleep(2 * idle_50ms)
+	// keeps min object count.
+	pool.EvictIdleObjects()
+
+	stats = pool.Stats()
+	if stats.DestroyedCount != 1 || stats.EvictedCount != 0 || stats.IdleObjectCount != 1 {
+		t.Fatalf("invalid counters after return, stats:%+v", stats)
+	}
+	if stats.MaxObjectCount != 2 || stats.MinObjectCount != 1 {
+		t.Fatalf("invalid limits, stats:%+v", stats)
+	}
+}
+
+func TestStats_Evicted(t *testing.T) {
+	pool, err := New(test_object_constructor,
+			WithEvictionInterval(0),
+			WithIdleTimeout(idle_50ms))
+	if err != nil {
+		t.Fatalf("New() create pool failed, err:%v", err)
+	}
+	defer pool.Close()
+
+	return_in_order(t, pool, 3)
+	time.Sleep(2 * idle_50ms)
+	pool.EvictIdleObjects()
+
+	stats := pool.Stats()
+	if stats.EvictedCount != 3 || stats.DestroyedCount != 3 || stats.ObjectCount != 0 {
+		t.Fatalf("invalid eviction counters, stats:%+v", stats)
+	}
+}
+
+// run with -race, accessors must not race with GetObject().
+func TestStats_Concurrent(t *testing.T) {
+	pool := new_test_object_pool(t, 0, 10)
+	defer pool.Close()
+
+	var wg sync.WaitGroup
+	ctx, cancel := context.WithTimeout(context.Background(), 100 * time.Millisecond)
+	defer cancel()
+	for idx := 0; idx < 4; idx += 1 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				object_holder, err := pool.GetObjectContext(ctx)
+				if err != nil {
+					if !errors.Is(err, context.DeadlineExceeded) {
+						t.Errorf("GetObjectContext() failed, err:%s", err)
+					}
+					return
+				}
+				pool.ReturnObject(object_holder)
+			}
+		}()
+	}
+
+	for ctx.Err() == nil {
+		stats := pool.Stats()
+		if stats.IdleObjectCount + stats.ActiveObjectCount != stats.ObjectCount {
+			t.Fatalf("inconsistent snapshot, stats:%+v", stats)
+		}
+		pool.GetObjectCount()
+		pool.GetIdleObjectCount()
+		pool.IsClosed()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,175 @@
+package ObjectPool
+
+import (
+	"context"
+	"time"
+)
+
+// Pool is a type-safe view of BasicPool, every object it lends is a T.
+type Pool[T any] struct {
+	pool *BasicPool
+}
+
+// Holder is the type-safe view of ObjectHolder.
+type Holder[T any] ObjectHolder
+
+func NewPool[T any](
+		min_object 	uint32,
+		max_object 	uint32,
+		idle_time 	time.Duration,
+		constructor func() (T, error),
+		destructor 	func(T),
+		idExtractor func(T) string,
+		opts 		...Option) (*Pool[T], error) {
+
+	// nil functions are passed through so NewObjectPool reports them.
+	var cons Constructor
+	if constructor != nil {
+		cons = func() (interface{}, error) {
+			return constructor()
+		}
+	}
+
+	var decons Destructor
+	if destructor != nil {
+		decons = func(object interface{}) {
+			destructor(typedObject[T](object))
+		}
+	}
+
+	var extractor IdExtractor
+	if idExtractor != nil {
+		extractor = func(object interface{}) string {
+			return idExtractor(typedObject[T](object))
+		}
+	}
+
+	pool, err := NewObjectPool(min_object, max_object, idle_time, cons, decons, extractor, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Pool[T]{pool: pool}, nil
+}
+
+// typedObject converts without panicking when a constructor returned a nil
+// interface value.
+func typedObject[T any](object interface{}) T {
+	typed, _ := object.(T)
+	return typed
+}
+
+func (p *Pool[T]) GetObject() (*Holder[T], error) {
+	object, err := p.pool.GetObject()
+	if err != nil {
+		return nil, err
+	}
+	return (*Holder[T])(object), nil
+}
+
+func (p *Pool[T]) GetObjectContext(ctx context.Context) (*Holder[T], error) {
+	object, err := p.pool.GetObjectContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return (*Holder[T])(object), nil
+}
+
+func (p *Pool[T]) GetObjectWithPriority(ctx context.Context, priority int) (*Holder[T], error) {
+	object, err := p.pool.GetObjectWithPriority(ctx, priority)
+	if err != nil {
+		return nil, err
+	}
+	return (*Holder[T])(object), nil
+}
+
+func (p *Pool[T]) ReturnObject(object *Holder[T]) error {
+	return p.pool.ReturnObject((*ObjectHolder)(object))
+}
+
+func (p *Pool[T]) Shutdown(ctx context.Context) error {
+	return p.pool.Shutdown(ctx)
+}
+
+func (p *Pool[T]) Close() {
+	p.pool.Close()
+}
+
+func (p *Pool[T]) IsClosed() bool {
+	return p.pool.IsClosed()
+}
+
+func (p *Pool[T]) GetObjectCount() uint32 {
+	return p.pool.GetObjectCount()
+}
+
+func (p *Pool[T]) GetIdleObjectCount() uint32 {
+	return p.pool.GetIdleObjectCount()
+}
+
+func (p *Pool[T]) GetMaxObjectCount() uint32 {
+	return p.pool.GetMaxObjectCount()
+}
+
+func (p *Pool[T]) GetMinObjectCount() uint32 {
+	return p.pool.GetMinObjectCount()
+}
+
+func (p *Pool[T]) GetIdleTime() time.Duration {
+	return p.pool.GetIdleTime()
+}
+
+func (p *Pool[T]) Stats() Stats {
+	return p.pool.Stats()
+}
+
+func (p *Pool[T]) Config() Config {
+	return p.pool.Config()
+}
+
+func (p *Pool[T]) SetMaxObjectCount(max_object uint32) error {
+	return p.pool.SetMaxObjectCount(max_object)
+}
+
+func (p *Pool[T]) SetMinObjectCount(min_object uint32) error {
+	return p.pool.SetMinObjectCount(min_object)
+}
+
+func (p *Pool[T]) SetIdleTime(idle_time time.Duration) error {
+	return p.pool.SetIdleTime(idle_time)
+}
+
+func (p *Pool[T]) Lookup(id string) (HolderInfo, bool) {
+	return p.pool.Lookup(id)
+}
+
+func (p *Pool[T]) InvalidateByID(id string) bool {
+	return p.pool.InvalidateByID(id)
+}
+
+func (p *Pool[T]) Objects() []HolderInfo {
+	return p.pool.Objects()
+}
+
+func (o *Holder[T]) ExtractObject() T {
+	return typedObject[T](o.object)
+}
+
+func (o *Holder[T]) GetId() string {
+	return (*ObjectHolder)(o).GetId()
+}
+
+func (o *Holder[T]) GetCreateTime() time.Time {
+	return (*ObjectHolder)(o).GetCreateTime()
+}
+
+func (o *Holder[T]) GetUseCount() uint64 {
+	return (*ObjectHolder)(o).GetUseCount()
+}
+
+func (o *Holder[T]) IsUsable() bool {
+	return (*ObjectHolder)(o).IsUsable()
+}
+
+func (o *Holder[T]) MarkUnusable() {
+	(*ObjectHolder)(o).MarkUnusable()
+}
@@ -0,0 +1,97 @@
+package ObjectPool
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+func typed_conn_constructor() (net.Conn, error) {
+	return net.DialTimeout(network, server_addr, idle_2s)
+}
+
+func typed_conn_destructor(conn net.Conn) {
+	conn.Close()
+}
+
+func typed_conn_id_extractor(conn net.Conn) string {
+	return fmt.Sprintf("%s_%s", conn.RemoteAddr(), conn.LocalAddr())
+}
+
+func TestNewPool_ConsNil(t *testing.T) {
+	_, err := NewPool[net.Conn](uint_512, uint_1024, idle_300s,
+					nil, typed_conn_destructor, typed_conn_id_extractor)
+	if err == nil {
+		t.Fatalf("NewPool() should checking constructor is not nil")
+	}
+}
+
+func TestPool_ReadWrite(t *testing.T) {
+	pool, err := NewPool(uint_512, uint_1024, idle_300s,
+					typed_conn_constructor, typed_conn_destructor, typed_conn_id_extractor)
+	if err != nil {
+		t.Fatalf("NewPool() create object failed, err:%v", err)
+	}
+	defer pool.Close()
+
+	object_holder, err := pool.GetObject()
+	if err != nil {
+		t.Fatalf("GetObject() failed, err:%s", err)
+	}
+
+	// no type assertion needed.
+	conn := object_holder.ExtractObject()
+	data_write := "test from typed client"
+	conn.SetDeadline(time.Now().Add(idle_2s))
+	if _, err := io.WriteString(conn, data_write); err != nil {
+		t.Fatalf("write data failed, err:%s", err)
+	}
+	data_read := make([]byte, len(data_write))
+	if _, err := io.ReadFull(conn, data_read); err != nil {
+		t.Fatalf("read data failed, err:%s", err)
+	}
+	if data_write != string(data_read) {
+		t.Fatalf("invalid retur data, expect:%s, ret:%s", data_write, string(data_read))
+	}
+
+	err = pool.ReturnObject(object_holder)
+	if err != nil {
+		t.Fatalf("Return Object failed, err:%v", err)
+	}
+	if pool.GetIdleObjectCount() != 1 {
+		t.Fatalf("Return Object Failed, expect:%d, get:%d", 1, pool.GetIdleObjectCount())
+	}
+}
+
+func TestPool_UseCountAndUnusable(t *testing.T) {
+	pool, err := NewPool(0, 1, idle_300s,
+					func() (*test_object, error) { return &test_object{}, nil },
+					func(*test_object) {},
+					func(object *test_object) string { return fmt.Sprintf("%p", object) })
+	if err != nil {
+		t.Fatalf("NewPool() create object failed, err:%v", err)
+	}
+	defer pool.Close()
+
+	first, err := pool.GetObject()
+	if err != nil {
+		t.Fatalf("GetObject() failed, err:%s", err)
+	}
+	pool.ReturnObject(first)
+
+	second, err := pool.GetObject()
+	if err != nil {
+		t.Fatalf("GetObject() failed, err:%s", err)
+	}
+	if second.ExtractObject() != first.ExtractObject() || second.GetUseCount() != 2 {
+		t.Fatalf("idle object should be reused, UseCount expect:%d, get:%d", 2, second.GetUseCount())
+	}
+
+	second.MarkUnusable()
+	pool.ReturnObject(second)
+	if pool.GetObjectCount() != 0 {
+		t.Fatalf("unusable object should be destroyed, ObjectCount:%d", pool.GetObjectCount())
+	}
+}
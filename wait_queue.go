@@ -0,0 +1,119 @@
+package ObjectPool
+
+import (
+	"container/heap"
+	"math"
+	"time"
+)
+
+type objectWaiter struct {
+	ready    chan objectRequest
+	priority int
+	// unix nanos of the enqueue, and its order among equal ranks.
+	enqueueTime int64
+	seq         uint64
+	// position in waitQueue.waiters, -1 once dequeued.
+	index int
+}
+
+// waitQueue is a heap of parked GetObjectContext() callers, the one with the
+// highest priority is served first. With aging, every aging period spent
+// waiting is worth one priority level, so low priority callers are not
+// starved. Equal ranks are served in FIFO order.
+type waitQueue struct {
+	waiters []*objectWaiter
+	aging   time.Duration
+	seq     uint64
+}
+
+func (q *waitQueue) Len() int {
+	return len(q.waiters)
+}
+
+func (q *waitQueue) Less(i, j int) bool {
+	a, b := q.waiters[i], q.waiters[j]
+	if q.aging > 0 {
+		// waited long enough, a low priority waiter ranks above later ones.
+		rank_a, rank_b := q.rank(a), q.rank(b)
+		if rank_a != rank_b {
+			return rank_a < rank_b
+		}
+	} else if a.priority != b.priority {
+		return a.priority > b.priority
+	}
+	return a.seq < b.seq
+}
+
+// rank is the enqueue time moved back by one aging period per priority
+// level, it saturates so huge priorities do not wrap around.
+func (q *waitQueue) rank(waiter *objectWaiter) int64 {
+	priority, aging := int64(waiter.priority), int64(q.aging)
+	var advance int64
+	switch {
+	case priority > 0 && priority > math.MaxInt64/aging:
+		advance = math.MaxInt64
+	case priority < 0 && priority < math.MinInt64/aging:
+		advance = math.MinInt64
+	default:
+		advance = priority * aging
+	}
+
+	switch {
+	case advance > 0 && waiter.enqueueTime < math.MinInt64+advance:
+		return math.MinInt64
+	case advance < 0 && waiter.enqueueTime > math.MaxInt64+advance:
+		return math.MaxInt64
+	}
+	return waiter.enqueueTime - advance
+}
+
+func (q *waitQueue) Swap(i, j int) {
+	q.waiters[i], q.waiters[j] = q.waiters[j], q.waiters[i]
+	q.waiters[i].index = i
+	q.waiters[j].index = j
+}
+
+func (q *waitQueue) Push(x interface{}) {
+	waiter := x.(*objectWaiter)
+	waiter.index = len(q.waiters)
+	q.waiters = append(q.waiters, waiter)
+}
+
+func (q *waitQueue) Pop() interface{} {
+	last := len(q.waiters) - 1
+	waiter := q.waiters[last]
+	q.waiters[last] = nil
+	q.waiters = q.waiters[:last]
+	waiter.index = -1
+	return waiter
+}
+
+func (q *waitQueue) enqueue(waiter *objectWaiter) {
+	q.seq += 1
+	waiter.seq = q.seq
+	waiter.enqueueTime = time.Now().UnixNano()
+	heap.Push(q, waiter)
+}
+
+// dequeue removes the waiter to serve next, the queue must not be empty.
+func (q *waitQueue) dequeue() *objectWaiter {
+	return heap.Pop(q).(*objectWaiter)
+}
+
+func (q *waitQueue) remove(waiter *objectWaiter) bool {
+	if waiter.index < 0 || waiter.index >= len(q.waiters) || q.waiters[waiter.index] != waiter {
+		return false
+	}
+	heap.Remove(q, waiter.index)
+	return true
+}
+
+// drain empties the queue and returns the waiters it held.
+func (q *waitQueue) drain() []*objectWaiter {
+	waiters := q.waiters
+	for _, waiter := range waiters {
+		waiter.index = -1
+	}
+	q.waiters = nil
+	return waiters
+}
@@ -0,0 +1,131 @@
+package ObjectPool
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+)
+
+func wait_waiter_count(t *testing.T, pool *BasicPool, count uint32) {
+	deadline := time.Now().Add(idle_2s)
+	for pool.Stats().WaiterCount != count {
+		if time.Now().After(deadline) {
+			t.Fatalf("waiter count mismatch, expect:%d, get:%d", count, pool.Stats().WaiterCount)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestPriority_HigherFirst(t *testing.T) {
+	pool, err := New(test_object_constructor, WithMaxObjects(1), WithPriorityAging(0))
+	if err != nil {
+		t.Fatalf("New() create pool failed, err:%v", err)
+	}
+	defer pool.Close()
+
+	object, _ := pool.GetObject()
+	served := make(chan int, 3)
+	borrow := func(priority int) {
+		ctx, cancel := context.WithTimeout(context.Background(), idle_2s)
+		defer cancel()
+		object, err := pool.GetObjectWithPriority(ctx, priority)
+		if err != nil {
+			served <- -1
+			return
+		}
+		served <- priority
+		pool.ReturnObject(object)
+	}
+	go borrow(0)
+	wait_waiter_count(t, pool, 1)
+	go borrow(5)
+	wait_waiter_count(t, pool, 2)
+	go borrow(10)
+	wait_waiter_count(t, pool, 3)
+
+	pool.ReturnObject(object)
+	for _, expect := range []int{10, 5, 0} {
+		if priority := <-served; priority != expect {
+			t.Fatalf("served order mismatch, expect:%d, get:%d", expect, priority)
+		}
+	}
+}
+
+func TestPriority_Cancel(t *testing.T) {
+	pool := new_test_object_pool(t, 0, 1)
+	defer pool.Close()
+
+	object, _ := pool.GetObject()
+	ctx, cancel := context.WithTimeout(context.Background(), idle_50ms)
+	defer cancel()
+	if _, err := pool.GetObjectWithPriority(ctx, 10); err != context.DeadlineExceeded {
+		t.Fatalf("waiter should time out, err:%v", err)
+	}
+	if pool.Stats().WaiterCount != 0 {
+		t.Fatalf("timed out waiter should be removed, waiters:%d", pool.Stats().WaiterCount)
+	}
+	pool.ReturnObject(object)
+}
+
+func TestWaitQueue_Aging(t *testing.T) {
+	queue := &waitQueue{aging: time.Millisecond}
+	low := &objectWaiter{priority: 0}
+	queue.enqueue(low)
+	// waited 10 aging periods.
+	low.enqueueTime -= int64(10 * time.Millisecond)
+
+	high := &objectWaiter{priority: 5}
+	queue.enqueue(high)
+	highest := &objectWaiter{priority: 20}
+	queue.enqueue(highest)
+
+	for _, expect := range []*objectWaiter{highest, low, high} {
+		if waiter := queue.dequeue(); waiter != expect {
+			t.Fatalf("dequeue order mismatch, expect priority:%d, get:%d", expect.priority, waiter.priority)
+		}
+	}
+}
+
+func TestWaitQueue_Remove(t *testing.T) {
+	queue := &waitQueue{}
+	waiters := make([]*objectWaiter, 4)
+	for idx := range waiters {
+		waiters[idx] = &objectWaiter{}
+		queue.enqueue(waiters[idx])
+	}
+	if !queue.remove(waiters[1]) || queue.remove(waiters[1]) {
+		t.Fatalf("waiter should be removed exactly once")
+	}
+	// equal priorities are served in FIFO order.
+	for _, expect := range []int{0, 2, 3} {
+		if waiter := queue.dequeue(); waiter != waiters[expect] {
+			t.Fatalf("dequeue order mismatch, expect:%d", expect)
+		}
+	}
+}
+
+func TestConfig_PriorityAging(t *testing.T) {
+	if _, err := New(test_object_constructor, WithPriorityAging(-time.Second)); err == nil {
+		t.Fatalf("New() should checking priority aging is not negative")
+	}
+}
+
+func TestWaitQueue_HugePriority(t *testing.T) {
+	queue := &waitQueue{aging: time.Second}
+	low := &objectWaiter{priority: 0}
+	queue.enqueue(low)
+	huge := &objectWaiter{priority: 10_000_000_000}
+	queue.enqueue(huge)
+	highest := &objectWaiter{priority: math.MaxInt}
+	queue.enqueue(highest)
+	lowest := &objectWaiter{priority: math.MinInt}
+	queue.enqueue(lowest)
+
+	// saturated priorities keep their order, the earlier waiter first.
+	for _, expect := range []*objectWaiter{huge, highest, low, lowest} {
+		if waiter := queue.dequeue(); waiter != expect {
+			t.Fatalf("dequeue order mismatch, expect priority:%d, get:%d", expect.priority, waiter.priority)
+		}
+	}
+}